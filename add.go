@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+func AddCommand() {
+	var args struct {
+		Alias    string `cli:"#R, alias, The alias of the project"`
+		Duration string `cli:"#R, duration, How long you worked, e.g. 1h30m, 45m or 1:30"`
+		Comment  string `cli:"comment, The comment for the time entry"`
+		Day      string `cli:"-d, --day, The day to book the time on, e.g. yesterday, last friday or 2026-10-01" default:"today"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	duration, err := ParseDurationInput(args.Duration)
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+	day, err := ParseDate(args.Day, time.Now())
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+
+	project, ok := findProjectByAlias(args.Alias)
+	if !ok {
+		fmt.Printf("Project alias %s'%s'%s not found 😱\nRun the %s'help projects alias'%s command to learn how to set an alias.\n", chalk.Red, args.Alias, chalk.Reset, chalk.Cyan, chalk.Reset)
+		os.Exit(1)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	err = recordAction(client, "add "+args.Alias+" "+args.Duration, func() error {
+		return addTimeEntry(client, project, day.Format(DayFormat), duration, args.Comment)
+	})
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	fmt.Printf("Added %s to %s%s%s on %s\n", formatHoursMinutes(duration), chalk.Green, project.Alias, chalk.Reset, day.Format(DayFormat))
+}
+
+// addTimeEntry books a finished time entry after the existing entries of the
+// day.
+func addTimeEntry(client *AerionClient, project ProjectConfig, day string, duration int, comment string) error {
+	timeEntries, err := client.GetTimeEntriesForDay(day)
+	if err != nil {
+		return err
+	}
+
+	created, err := client.CreateTimeEntry(aerion.NewTimeEntry{
+		ProjectId:    project.Id,
+		Day:          day,
+		Duration:     duration,
+		Sorting:      nextSorting(timeEntries),
+		Running:      false,
+		Comment:      comment,
+		TaskId:       project.DefaultTaskId,
+		TrackingType: "WORK",
+		UserId:       client.UserId,
+	})
+	if err != nil {
+		return err
+	}
+
+	client.Action.Created(created)
+	return nil
+}
+
+// nextSorting returns the sorting that places a new entry after all the given
+// entries of a day. Entries may have gaps in their sorting, e.g. after one was
+// deleted, so counting them isn't enough.
+func nextSorting(timeEntries []aerion.TimeEntry) int {
+	sorting := len(timeEntries)
+	for _, timeEntry := range timeEntries {
+		sorting = max(sorting, timeEntry.Sorting)
+	}
+	return sorting + 1
+}
@@ -0,0 +1,526 @@
+// Package aerion is a client for the Aerion time tracking API. It handles the
+// OAuth2 tokens, retries flaky requests and pages through listings, so tools
+// can read and book time entries without dealing with HTTP.
+package aerion
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	Raw          string `json:"raw"`
+	Status       int    `json:"status"`
+}
+
+var publicApiToken = "Basic " + base64.StdEncoding.EncodeToString([]byte("coffeecup-cli:public"))
+
+// TokenSource provides the OAuth2 tokens used by a Client and persists
+// the ones it receives from the token endpoint.
+type TokenSource interface {
+	AccessToken() string
+	RefreshToken() string
+	ExpiresAt() int64
+	StoreTokens(accessToken string, refreshToken string, expiresIn int) error
+}
+
+// Client talks to the Aerion API of a single company.
+type Client struct {
+	BaseUrl    string
+	HttpClient *http.Client
+	Tokens     TokenSource
+	Retry      RetryPolicy
+	// Debug receives a trace of all API traffic with secrets redacted. Nil
+	// disables tracing.
+	Debug io.Writer
+	// UserId is the user whose time entries are read and written.
+	UserId int
+}
+
+// DefaultTimeout limits how long a single request may take. A flaky VPN
+// often leaves connections hanging instead of failing them, and only requests
+// that fail are retried.
+const DefaultTimeout = 20 * time.Second
+
+// NewClient creates a client for the API at baseUrl. Without an httpClient, a
+// plain one with DefaultTimeout is used.
+func NewClient(baseUrl string, httpClient *http.Client, tokens TokenSource) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Client{
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		HttpClient: httpClient,
+		Tokens:     tokens,
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+// newRequest creates an authenticated request against the API.
+func (c *Client) newRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.BaseUrl+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.Tokens.AccessToken())
+	return req, nil
+}
+
+// do sends an authenticated request and returns an *APIError if the API
+// rejects it. Transient failures of idempotent requests are retried. If the
+// access token is rejected, the tokens are refreshed once and the request is
+// replayed. If Aerion can't be reached for the refresh, that error is returned.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, body, err := c.sendWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+	err = checkResponse(req, resp, body)
+	if err == nil {
+		return resp, nil
+	}
+	if !isUnauthorized(err) {
+		return nil, err
+	}
+
+	if refreshErr := c.LoginWithRefreshToken(); refreshErr != nil {
+		// the session may well be valid, Aerion just couldn't be asked
+		if IsOffline(refreshErr) {
+			return nil, refreshErr
+		}
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", "Bearer "+c.Tokens.AccessToken())
+
+	resp, body, err = c.sendWithRetry(retry)
+	if err != nil {
+		return nil, err
+	}
+	err = checkResponse(retry, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// doJSON sends an authenticated request and decodes the JSON response into v.
+// v may be nil if the response body isn't needed.
+func (c *Client) doJSON(req *http.Request, v any) error {
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		return nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("%s %s returned an invalid response: %w", req.Method, req.URL.Path, err)
+	}
+	return nil
+}
+
+// send performs the request and buffers the response body, so it can be
+// inspected before it is handed to the caller.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	start := time.Now()
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		c.logExchange(req, nil, nil, err, time.Since(start))
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	c.logExchange(req, resp, body, err, time.Since(start))
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, body, nil
+}
+
+// isUnauthorized reports whether the API rejected the access token.
+func isUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// requestTokens calls the oauth2 token endpoint and stores the received tokens.
+func (c *Client) requestTokens(reqBody url.Values) error {
+	req, err := http.NewRequest("POST", c.BaseUrl+"/oauth2/token", strings.NewReader(reqBody.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", publicApiToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("companyurl", c.BaseUrl)
+
+	resp, body, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	err = checkResponse(req, resp, body)
+	if err != nil {
+		return err
+	}
+
+	var responseBody TokenResponse
+	err = json.Unmarshal(body, &responseBody)
+	if err != nil {
+		return fmt.Errorf("%s %s returned an invalid response: %w", req.Method, req.URL.Path, err)
+	}
+
+	return c.Tokens.StoreTokens(responseBody.AccessToken, responseBody.RefreshToken, responseBody.ExpiresIn)
+}
+
+func (c *Client) EnsureLoggedIn() error {
+	if c.Tokens.ExpiresAt() > time.Now().Unix() {
+		return nil
+	}
+
+	return c.LoginWithRefreshToken()
+}
+
+func (c *Client) LoginWithPassword(username string, password string) error {
+	return c.requestTokens(url.Values{
+		"grant_type": []string{"password"},
+		"username":   []string{username},
+		"password":   []string{password},
+	})
+}
+
+func (c *Client) LoginWithRefreshToken() error {
+	refreshToken := c.Tokens.RefreshToken()
+	if refreshToken == "" {
+		return ErrLoginRequired
+	}
+	return c.requestTokens(url.Values{
+		"grant_type":    []string{"refresh_token"},
+		"refresh_token": []string{refreshToken},
+	})
+}
+
+type User struct {
+	Id    int    `json:"id"`
+	Email string `json:"email"`
+}
+
+type UserResponse struct {
+	User User
+}
+
+func (c *Client) GetUser() (User, error) {
+	req, err := c.newRequest("GET", "/v1/users/me", nil)
+	if err != nil {
+		return User{}, err
+	}
+
+	var responseBody UserResponse
+	err = c.doJSON(req, &responseBody)
+	if err != nil {
+		return User{}, err
+	}
+
+	return responseBody.User, nil
+}
+
+type Project struct {
+	Id   int
+	Name string
+}
+
+type ProjectsResponse struct {
+	Projects []Project `json:"projects"`
+	Meta     struct {
+		Total int `json:"total"`
+	} `json:"Meta"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+	Raw    string `json:"raw"`
+}
+
+func (c *Client) GetProjects() ([]Project, error) {
+	return c.ListProjects().All()
+}
+
+// ListProjects pages through all active projects.
+func (c *Client) ListProjects() *PageIterator[Project] {
+	return NewPageIterator(func(limit int, skip int) ([]Project, int, error) {
+		query := url.Values{
+			"status": []string{"1"},
+			"limit":  []string{strconv.Itoa(limit)},
+			"skip":   []string{strconv.Itoa(skip)},
+		}
+		req, err := c.newRequest("GET", "/v1/projects?"+query.Encode(), nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var projectsResponse ProjectsResponse
+		err = c.doJSON(req, &projectsResponse)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return projectsResponse.Projects, projectsResponse.Meta.Total, nil
+	})
+}
+
+type TimeEntry struct {
+	Id           int    `json:"id"`
+	ProjectId    int    `json:"project"`
+	TaskId       int    `json:"task"`
+	TeamId       int    `json:"team"`
+	UserId       int    `json:"user"`
+	Comment      string `json:"comment"`
+	Running      bool   `json:"running"`
+	CreatedAt    string `json:"createdAt"`
+	Day          string `json:"day"`
+	Duration     int    `json:"duration"`
+	Sorting      int    `json:"sorting"`
+	TrackingType string `json:"trackingType"`
+}
+
+type TimeEntriesResponse struct {
+	TimeEntries []TimeEntry `json:"timeEntries"`
+	Meta        struct {
+		Total int `json:"total"`
+	} `json:"Meta"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+	Raw    string `json:"raw"`
+}
+
+func (c *Client) GetTodaysTimeEntries() ([]TimeEntry, error) {
+	today := time.Now().Format("2006-01-02")
+	return c.GetTimeEntriesForDay(today)
+}
+
+func (c *Client) GetTimeEntriesForDay(day string) ([]TimeEntry, error) {
+	return c.ListTimeEntriesForDay(day).All()
+}
+
+// ListTimeEntriesForDay pages through the user's time entries of the given day
+// in the order they are shown in Aerion.
+func (c *Client) ListTimeEntriesForDay(day string) *PageIterator[TimeEntry] {
+	return c.listTimeEntries(url.Values{
+		"user": []string{strconv.Itoa(c.UserId)},
+		"day":  []string{day},
+		"sort": []string{"day ASC,sorting ASC"},
+	})
+}
+
+func (c *Client) GetTimeEntriesBetween(from string, to string) ([]TimeEntry, error) {
+	return c.ListTimeEntriesBetween(from, to).All()
+}
+
+// ListTimeEntriesBetween pages through the user's time entries from the first
+// to the last day, both inclusive, ordered by day.
+func (c *Client) ListTimeEntriesBetween(from string, to string) *PageIterator[TimeEntry] {
+	// loose criteria like user are ignored once there is a where clause
+	where, _ := json.Marshal(map[string]any{
+		"user": c.UserId,
+		"day":  map[string]string{">=": from, "<=": to},
+	})
+	return c.listTimeEntries(url.Values{
+		"where": []string{string(where)},
+		"sort":  []string{"day ASC,sorting ASC"},
+	})
+}
+
+func (c *Client) listTimeEntries(query url.Values) *PageIterator[TimeEntry] {
+	return NewPageIterator(func(limit int, skip int) ([]TimeEntry, int, error) {
+		pageQuery := url.Values{}
+		for key, values := range query {
+			pageQuery[key] = values
+		}
+		pageQuery.Set("limit", strconv.Itoa(limit))
+		pageQuery.Set("skip", strconv.Itoa(skip))
+
+		req, err := c.newRequest("GET", "/v1/timeentries?"+pageQuery.Encode(), nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var timeEntriesResponse TimeEntriesResponse
+		err = c.doJSON(req, &timeEntriesResponse)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return timeEntriesResponse.TimeEntries, timeEntriesResponse.Meta.Total, nil
+	})
+}
+
+func (c *Client) GetLastTimeEntryForProject(projectId int) (TimeEntry, error) {
+	userId := strconv.Itoa(c.UserId)
+	req, err := c.newRequest("GET", "/v1/timeentries?limit=1&user="+userId+"&project="+strconv.Itoa(projectId)+"&sort=day%20DESC", nil)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	var timeEntriesResponse TimeEntriesResponse
+	err = c.doJSON(req, &timeEntriesResponse)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+	if len(timeEntriesResponse.TimeEntries) == 0 {
+		return TimeEntry{}, fmt.Errorf("no time entries found")
+	}
+
+	return timeEntriesResponse.TimeEntries[0], nil
+}
+
+func (c *Client) GetTimeEntry(id int) (TimeEntry, error) {
+	req, err := c.newRequest("GET", "/v1/timeEntries/"+strconv.Itoa(id), nil)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	var timeEntryResponse struct {
+		TimeEntry TimeEntry `json:"timeEntry"`
+	}
+	err = c.doJSON(req, &timeEntryResponse)
+	return timeEntryResponse.TimeEntry, err
+}
+
+func (c *Client) UpdateTimeEntry(timeEntry TimeEntry) error {
+	type TimeEntryUpdate struct {
+		TimeEntry TimeEntry `json:"timeEntry"`
+	}
+
+	timeEntryToBeUpdated := TimeEntryUpdate{
+		TimeEntry: timeEntry,
+	}
+	payload, err := json.Marshal(timeEntryToBeUpdated)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest("PUT", "/v1/timeEntries/"+strconv.Itoa(timeEntry.Id), bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	return c.doJSON(req, nil)
+}
+
+func (c *Client) DeleteTimeEntry(id int) error {
+	req, err := c.newRequest("DELETE", "/v1/timeEntries/"+strconv.Itoa(id), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.doJSON(req, nil)
+}
+
+type NewTimeEntry struct {
+	ProjectId    int    `json:"project"`
+	Comment      string `json:"comment"`
+	Day          string `json:"day"`
+	Running      bool   `json:"running"`
+	Duration     int    `json:"duration"`
+	Sorting      int    `json:"sorting"`
+	TaskId       int    `json:"task"`
+	TrackingType string `json:"trackingType"`
+	UserId       int    `json:"user"`
+}
+
+// CreateTimeEntry creates a new time entry and returns it. POST requests
+// aren't retried blindly: after a transient failure the day's entries are
+// checked first, as the entry might have been created although the response
+// got lost.
+func (c *Client) CreateTimeEntry(timeEntry NewTimeEntry) (TimeEntry, error) {
+	type TimeEntryCreation struct {
+		TimeEntry NewTimeEntry `json:"timeEntry"`
+	}
+
+	timeEntryToBeCreated := TimeEntryCreation{
+		TimeEntry: timeEntry,
+	}
+	payload, err := json.Marshal(timeEntryToBeCreated)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	for retry := 1; ; retry++ {
+		req, err := c.newRequest("POST", "/v1/timeEntries", bytes.NewBuffer(payload))
+		if err != nil {
+			return TimeEntry{}, err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+		var timeEntryResponse struct {
+			TimeEntry TimeEntry `json:"timeEntry"`
+		}
+		err = c.doJSON(req, &timeEntryResponse)
+		if !isTransient(err) || retry >= c.Retry.MaxAttempts {
+			return timeEntryResponse.TimeEntry, err
+		}
+
+		var retryAfter time.Duration
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			retryAfter = apiErr.RetryAfter
+		}
+		delay, ok := c.Retry.delay(retry, retryAfter)
+		if !ok {
+			return TimeEntry{}, err
+		}
+		time.Sleep(delay)
+
+		created, found, checkErr := c.findTimeEntry(timeEntry)
+		if checkErr != nil {
+			return TimeEntry{}, err
+		}
+		if found {
+			return created, nil
+		}
+	}
+}
+
+// findTimeEntry looks for an entry matching the given new time entry on its
+// day.
+func (c *Client) findTimeEntry(timeEntry NewTimeEntry) (TimeEntry, bool, error) {
+	timeEntries, err := c.GetTimeEntriesForDay(timeEntry.Day)
+	if err != nil {
+		return TimeEntry{}, false, err
+	}
+
+	for _, existing := range timeEntries {
+		if existing.ProjectId == timeEntry.ProjectId &&
+			existing.TaskId == timeEntry.TaskId &&
+			existing.Sorting == timeEntry.Sorting &&
+			existing.Comment == timeEntry.Comment &&
+			existing.Running == timeEntry.Running {
+			return existing, true, nil
+		}
+	}
+	return TimeEntry{}, false, nil
+}
@@ -0,0 +1,179 @@
+package aerion
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticTokenSource struct {
+	accessToken  string
+	refreshToken string
+	expiresAt    int64
+}
+
+func (s *staticTokenSource) AccessToken() string  { return s.accessToken }
+func (s *staticTokenSource) RefreshToken() string { return s.refreshToken }
+func (s *staticTokenSource) ExpiresAt() int64     { return s.expiresAt }
+func (s *staticTokenSource) StoreTokens(accessToken string, refreshToken string, expiresIn int) error {
+	s.accessToken = accessToken
+	s.refreshToken = refreshToken
+	return nil
+}
+
+func TestClientSendsBearerToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/v1/users/me" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"user":{"id":42,"email":"jane@example.com"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: "secret"})
+	user, err := client.GetUser()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if authorization != "Bearer secret" {
+		t.Fatalf("expected bearer token header, got %q", authorization)
+	}
+	if user.Id != 42 || user.Email != "jane@example.com" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+}
+
+func TestClientRefreshesTokenOnUnauthorized(t *testing.T) {
+	fake := NewFakeServer()
+	fake.AddProject("Project One")
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	tokens := &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken}
+	client := NewClient(server.URL, server.Client(), tokens)
+
+	fake.ExpireAccessToken()
+	projects, err := client.GetProjects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(projects) != 1 || projects[0].Name != "Project One" {
+		t.Fatalf("unexpected projects %+v", projects)
+	}
+	if tokens.accessToken == accessToken {
+		t.Fatal("expected the access token to be refreshed")
+	}
+}
+
+func TestClientReplaysRequestBodyAfterRefresh(t *testing.T) {
+	fake := NewFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken})
+
+	fake.ExpireAccessToken()
+	_, err := client.CreateTimeEntry(NewTimeEntry{ProjectId: 100, Day: "2024-01-02", Comment: "replayed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	timeEntries := fake.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].Comment != "replayed" {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+}
+
+func TestClientRequiresLoginWhenRefreshFails(t *testing.T) {
+	fake := NewFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken})
+
+	fake.ExpireAccessToken()
+	fake.RevokeRefreshToken()
+	_, err := client.GetProjects()
+	if !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("expected ErrLoginRequired, got %v", err)
+	}
+}
+
+func TestClientIsOfflineWhenRefreshIsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			// drop the connection like a flaky VPN
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: "expired", refreshToken: "refresh"})
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+	_, err := client.GetProjects()
+	if !IsOffline(err) || errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("expected an offline error, got %v", err)
+	}
+}
+
+func TestClientTreatsBodyStatusAsUnauthorized(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			w.Write([]byte(`{"access_token":"fresh","refresh_token":"refresh","expires_in":3600}`))
+			return
+		}
+		requests++
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.Write([]byte(`{"status":401,"error":"unauthorized","raw":"expired"}`))
+			return
+		}
+		w.Write([]byte(`{"timeEntries":[{"id":1}],"Meta":{"total":1}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: "stale", refreshToken: "refresh"})
+	timeEntries, err := client.GetTimeEntriesForDay("2024-01-02")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests != 2 || len(timeEntries) != 1 {
+		t.Fatalf("expected a replayed request, got %d requests and %+v", requests, timeEntries)
+	}
+}
+
+func TestClientListsOnlyOwnTimeEntriesBetween(t *testing.T) {
+	fake := NewFakeServer()
+	project := fake.AddProject("Project One")
+	fake.AddTimeEntry(TimeEntry{ProjectId: project.Id, UserId: 1, Day: "2026-10-01", Duration: 3600})
+	fake.AddTimeEntry(TimeEntry{ProjectId: project.Id, UserId: 2, Day: "2026-10-02", Duration: 1800})
+	fake.AddTimeEntry(TimeEntry{ProjectId: project.Id, UserId: 1, Day: "2026-10-09", Duration: 600})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken})
+	client.UserId = 1
+
+	timeEntries, err := client.GetTimeEntriesBetween("2026-10-01", "2026-10-07")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(timeEntries) != 1 || timeEntries[0].UserId != 1 || timeEntries[0].Day != "2026-10-01" {
+		t.Fatalf("expected only the user's entry of the range, got %+v", timeEntries)
+	}
+}
@@ -0,0 +1,73 @@
+package aerion
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// secretFieldRegex matches secrets in JSON bodies (`"password":"..."`) and in
+// form encoded bodies (`password=...`).
+var secretFieldRegex = regexp.MustCompile(`("(?:access_token|refresh_token|password)"\s*:\s*)"(?:[^"\\]|\\.)*"|((?:^|&)(?:access_token|refresh_token|password)=)[^&]*`)
+
+// redactSecrets replaces tokens and passwords in a request or response body.
+func redactSecrets(body string) string {
+	return secretFieldRegex.ReplaceAllStringFunc(body, func(match string) string {
+		submatches := secretFieldRegex.FindStringSubmatch(match)
+		if submatches[1] != "" {
+			return submatches[1] + `"` + redacted + `"`
+		}
+		return submatches[2] + redacted
+	})
+}
+
+// redactAuthorization keeps the scheme of an Authorization header but hides
+// the credentials.
+func redactAuthorization(value string) string {
+	scheme, _, found := strings.Cut(value, " ")
+	if !found {
+		return redacted
+	}
+	return scheme + " " + redacted
+}
+
+// logExchange writes a request and its outcome to the client's debug output.
+func (c *Client) logExchange(req *http.Request, resp *http.Response, respBody []byte, err error, duration time.Duration) {
+	if c.Debug == nil {
+		return
+	}
+
+	fmt.Fprintf(c.Debug, "[debug] → %s %s\n", req.Method, req.URL)
+	for _, name := range []string{"Authorization", "Content-Type"} {
+		value := req.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		if name == "Authorization" {
+			value = redactAuthorization(value)
+		}
+		fmt.Fprintf(c.Debug, "[debug]   %s: %s\n", name, value)
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ := io.ReadAll(body)
+			if len(reqBody) > 0 {
+				fmt.Fprintf(c.Debug, "[debug]   %s\n", redactSecrets(string(reqBody)))
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(c.Debug, "[debug] ← failed after %s: %v\n", duration.Round(time.Millisecond), err)
+		return
+	}
+	fmt.Fprintf(c.Debug, "[debug] ← %s (%s)\n", resp.Status, duration.Round(time.Millisecond))
+	if len(respBody) > 0 {
+		fmt.Fprintf(c.Debug, "[debug]   %s\n", redactSecrets(strings.TrimSpace(string(respBody))))
+	}
+}
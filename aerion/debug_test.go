@@ -0,0 +1,53 @@
+package aerion
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	tests := map[string]string{
+		`grant_type=password&username=jane&password=hunter2`:              `grant_type=password&username=jane&password=[REDACTED]`,
+		`grant_type=refresh_token&refresh_token=abc`:                      `grant_type=refresh_token&refresh_token=[REDACTED]`,
+		`{"access_token":"abc","refresh_token": "def","expires_in":3600}`: `{"access_token":"[REDACTED]","refresh_token": "[REDACTED]","expires_in":3600}`,
+		`{"comment":"password reset flow"}`:                               `{"comment":"password reset flow"}`,
+	}
+
+	for body, expected := range tests {
+		if redactedBody := redactSecrets(body); redactedBody != expected {
+			t.Errorf("expected %q, got %q", expected, redactedBody)
+		}
+	}
+}
+
+func TestClientDebugTraceRedactsSecrets(t *testing.T) {
+	fake := NewFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	tokens := &staticTokenSource{}
+	client := NewClient(server.URL, server.Client(), tokens)
+	var trace bytes.Buffer
+	client.Debug = &trace
+
+	if err := client.LoginWithPassword(fake.Username, fake.Password); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetUser(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := trace.String()
+	for _, expected := range []string{"POST " + server.URL + "/oauth2/token", "GET " + server.URL + "/v1/users/me", "200 OK", "Bearer [REDACTED]", "Basic [REDACTED]", "dev@example.com"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in trace:\n%s", expected, out)
+		}
+	}
+	for _, secret := range []string{"password=" + fake.Password, tokens.accessToken, tokens.refreshToken} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %q to be redacted in trace:\n%s", secret, out)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package aerion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrLoginRequired is returned when the API rejects the access token and it
+// can't be refreshed either.
+var ErrLoginRequired = errors.New("the session has expired, please login again")
+
+// APIError is returned when the Aerion API rejects a request. Use errors.As to
+// get hold of it and its Is* methods to tell the kinds of failures apart.
+type APIError struct {
+	// StatusCode is the HTTP status, or the status from the response body if
+	// the API reported a failure there.
+	StatusCode int
+	// Endpoint is the method and path of the failed request, e.g.
+	// "GET /v1/projects".
+	Endpoint string
+	// Code is the `error` field of the response body.
+	Code string
+	// Raw is the `raw` field of the response body, which usually holds a
+	// human readable description.
+	Raw string
+	// RetryAfter is the delay the API asked for before trying again, if any.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	message := e.Raw
+	if message == "" {
+		message = e.Code
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s failed with status %d: %s", e.Endpoint, e.StatusCode, message)
+}
+
+// Unwrap lets errors.Is(err, ErrLoginRequired) match rejected access tokens.
+func (e *APIError) Unwrap() error {
+	if e.StatusCode == http.StatusUnauthorized {
+		return ErrLoginRequired
+	}
+	return nil
+}
+
+// IsAuth reports whether the request was rejected because of missing or
+// insufficient credentials.
+func (e *APIError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsValidation reports whether the API rejected the submitted data.
+func (e *APIError) IsValidation() bool {
+	return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity || e.StatusCode == http.StatusConflict
+}
+
+// IsNotFound reports whether the requested resource doesn't exist.
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsServer reports whether the API failed on its side.
+func (e *APIError) IsServer() bool {
+	return e.StatusCode >= 500
+}
+
+// IsOffline reports whether a request failed because the API couldn't be
+// reached at all, as opposed to the API rejecting it: the connection was
+// refused, reset or timed out, or the host couldn't be resolved. Failures that
+// don't go away by waiting, like an untrusted certificate or an invalid URL,
+// aren't.
+func IsOffline(err error) bool {
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	// TLS alerts sent by the server, e.g. for a rejected client certificate,
+	// are reported as "remote error"
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op != "remote error" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// checkResponse returns an *APIError if the response signals a failure, either
+// with its HTTP status or with the status/error fields of its body.
+func checkResponse(req *http.Request, resp *http.Response, body []byte) error {
+	var responseBody struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+		Raw    string `json:"raw"`
+	}
+	json.Unmarshal(body, &responseBody)
+
+	statusCode := resp.StatusCode
+	if responseBody.Status >= 400 {
+		statusCode = responseBody.Status
+	}
+	if statusCode < 400 && responseBody.Error == "" {
+		return nil
+	}
+	if statusCode < 400 {
+		statusCode = http.StatusBadRequest
+	}
+
+	return &APIError{
+		StatusCode: statusCode,
+		Endpoint:   req.Method + " " + req.URL.Path,
+		Code:       responseBody.Error,
+		Raw:        responseBody.Raw,
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+}
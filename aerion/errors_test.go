@@ -0,0 +1,117 @@
+package aerion
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+func TestCheckResponseUsesBodyStatus(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/v1/timeEntries/1", nil)
+	resp := &http.Response{StatusCode: http.StatusOK}
+
+	err := checkResponse(req, resp, []byte(`{"status":404,"error":"E_NOT_FOUND","raw":"no such entry"}`))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if !apiErr.IsNotFound() || apiErr.Endpoint != "PUT /v1/timeEntries/1" || apiErr.Raw != "no such entry" {
+		t.Fatalf("unexpected APIError %+v", apiErr)
+	}
+}
+
+func TestCheckResponseAcceptsSuccess(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v1/projects", nil)
+	resp := &http.Response{StatusCode: http.StatusOK}
+
+	if err := checkResponse(req, resp, []byte(`{"projects":[]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAPIErrorKinds(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		check      func(*APIError) bool
+	}{
+		{http.StatusUnauthorized, (*APIError).IsAuth},
+		{http.StatusForbidden, (*APIError).IsAuth},
+		{http.StatusBadRequest, (*APIError).IsValidation},
+		{http.StatusUnprocessableEntity, (*APIError).IsValidation},
+		{http.StatusNotFound, (*APIError).IsNotFound},
+		{http.StatusBadGateway, (*APIError).IsServer},
+	}
+
+	for _, test := range tests {
+		apiErr := &APIError{StatusCode: test.statusCode}
+		if !test.check(apiErr) {
+			t.Errorf("unexpected kind for status %d", test.statusCode)
+		}
+	}
+
+	if !errors.Is(&APIError{StatusCode: http.StatusUnauthorized}, ErrLoginRequired) {
+		t.Error("expected a 401 to require a new login")
+	}
+	if errors.Is(&APIError{StatusCode: http.StatusForbidden}, ErrLoginRequired) {
+		t.Error("expected a 403 not to require a new login")
+	}
+}
+
+func TestClientReturnsAPIErrorForUnknownTimeEntry(t *testing.T) {
+	fake := NewFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken})
+
+	err := client.UpdateTimeEntry(TimeEntry{Id: 99})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Fatalf("expected a not found APIError, got %v", err)
+	}
+}
+
+func TestIsOffline(t *testing.T) {
+	refused := &url.Error{Op: "Get", URL: "https://aerion.test", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	if !IsOffline(refused) {
+		t.Error("a refused connection should be offline")
+	}
+	noHost := &url.Error{Op: "Get", URL: "https://aerion.test", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "aerion.test"}}}
+	if !IsOffline(noHost) {
+		t.Error("an unknown host should be offline")
+	}
+	scheme := &url.Error{Op: "Get", URL: "htps://aerion.test", Err: errors.New(`unsupported protocol scheme "htps"`)}
+	if IsOffline(scheme) {
+		t.Error("an invalid URL shouldn't be offline")
+	}
+	alert := &url.Error{Op: "Get", URL: "https://aerion.test", Err: &net.OpError{Op: "remote error", Err: errors.New("tls: bad certificate")}}
+	if IsOffline(alert) {
+		t.Error("a rejected client certificate shouldn't be offline")
+	}
+	if IsOffline(&APIError{StatusCode: http.StatusServiceUnavailable}) {
+		t.Error("an API error shouldn't be offline")
+	}
+}
+
+func TestIsOfflineIgnoresUntrustedCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	if err == nil {
+		t.Fatal("expected the self-signed certificate to be rejected")
+	}
+	if IsOffline(err) {
+		t.Errorf("an untrusted certificate shouldn't be offline: %v", err)
+	}
+	if isTransient(err) {
+		t.Errorf("an untrusted certificate shouldn't be retried: %v", err)
+	}
+}
@@ -0,0 +1,450 @@
+package aerion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeServer is an in-memory stand-in for the Aerion API. It implements just
+// enough of the oauth2 token, user, project and time entry endpoints to run
+// the CLI against it in tests and during offline development.
+type FakeServer struct {
+	Username string
+	Password string
+	User     User
+	// MaxPageSize caps the number of items returned per listing page, like
+	// the real API does. Zero means no cap.
+	MaxPageSize int
+
+	mu           sync.Mutex
+	projects     []Project
+	timeEntries  []TimeEntry
+	runningSince map[int]time.Time
+	nextId       int
+	tokenCount   int
+	accessToken  string
+	refreshToken string
+
+	failures       int
+	failureStatus  int
+	droppedReplies int
+}
+
+func NewFakeServer() *FakeServer {
+	return &FakeServer{
+		Username:     "dev",
+		Password:     "dev",
+		User:         User{Id: 1, Email: "dev@example.com"},
+		runningSince: make(map[int]time.Time),
+		nextId:       1,
+	}
+}
+
+// AddProject registers a new active project and returns it.
+func (s *FakeServer) AddProject(name string) Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project := Project{Id: 100 + len(s.projects), Name: name}
+	s.projects = append(s.projects, project)
+	return project
+}
+
+// IssueTokens creates a new token pair as if the user had logged in.
+func (s *FakeServer) IssueTokens() (accessToken string, refreshToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.issueTokens()
+}
+
+func (s *FakeServer) issueTokens() (string, string) {
+	s.tokenCount++
+	s.accessToken = "fake-access-token-" + strconv.Itoa(s.tokenCount)
+	s.refreshToken = "fake-refresh-token-" + strconv.Itoa(s.tokenCount)
+	return s.accessToken, s.refreshToken
+}
+
+// ExpireAccessToken invalidates the current access token. The refresh token
+// stays valid.
+func (s *FakeServer) ExpireAccessToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accessToken = "expired-" + s.accessToken
+}
+
+// RevokeRefreshToken invalidates the current refresh token.
+func (s *FakeServer) RevokeRefreshToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshToken = ""
+}
+
+// FailRequests makes the next count API requests fail with the given status
+// without handling them.
+func (s *FakeServer) FailRequests(count int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = count
+	s.failureStatus = status
+}
+
+// DropResponses handles the next count API requests but answers them with a
+// 502, as if the response got lost on its way back.
+func (s *FakeServer) DropResponses(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.droppedReplies = count
+}
+
+// TimeEntries returns a snapshot of all stored time entries, ordered by day
+// and sorting.
+func (s *FakeServer) TimeEntries() []TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timeEntries := make([]TimeEntry, len(s.timeEntries))
+	for i, timeEntry := range s.timeEntries {
+		timeEntries[i] = s.withElapsedDuration(timeEntry)
+	}
+	sortTimeEntries(timeEntries, false)
+	return timeEntries
+}
+
+// AddTimeEntry stores a time entry, e.g. to seed past days, and
+// returns it with its assigned ID.
+func (s *FakeServer) AddTimeEntry(timeEntry TimeEntry) TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timeEntry.Id = s.nextId
+	timeEntry.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.nextId++
+	if timeEntry.Running {
+		s.runningSince[timeEntry.Id] = time.Now()
+	}
+	s.timeEntries = append(s.timeEntries, timeEntry)
+	return timeEntry
+}
+
+// withElapsedDuration adds the time a running entry has been running since it
+// was last stored to its duration.
+func (s *FakeServer) withElapsedDuration(timeEntry TimeEntry) TimeEntry {
+	if since, ok := s.runningSince[timeEntry.Id]; ok && timeEntry.Running {
+		timeEntry.Duration += int(time.Since(since).Seconds())
+	}
+	return timeEntry
+}
+
+func sortTimeEntries(timeEntries []TimeEntry, dayDescending bool) {
+	slices.SortStableFunc(timeEntries, func(a, b TimeEntry) int {
+		if a.Day != b.Day {
+			if dayDescending {
+				return strings.Compare(b.Day, a.Day)
+			}
+			return strings.Compare(a.Day, b.Day)
+		}
+		return a.Sorting - b.Sorting
+	})
+}
+
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.ToLower(strings.TrimSuffix(r.URL.Path, "/"))
+	if path == "/oauth2/token" && r.Method == "POST" {
+		s.handleToken(w, r)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+s.accessToken || s.accessToken == "" {
+		writeFakeError(w, http.StatusUnauthorized, "unauthorized", "Invalid or expired access token")
+		return
+	}
+
+	if s.failures > 0 {
+		s.failures--
+		writeFakeError(w, s.failureStatus, "unavailable", "Injected failure")
+		return
+	}
+	if s.droppedReplies > 0 {
+		s.droppedReplies--
+		s.route(discardResponse{header: http.Header{}}, r, path)
+		writeFakeError(w, http.StatusBadGateway, "bad_gateway", "Injected lost response")
+		return
+	}
+
+	s.route(w, r, path)
+}
+
+// discardResponse is a ResponseWriter that drops the response, as if it got
+// lost on its way to the client.
+type discardResponse struct {
+	header http.Header
+}
+
+func (d discardResponse) Header() http.Header         { return d.header }
+func (d discardResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (d discardResponse) WriteHeader(statusCode int)  {}
+
+func (s *FakeServer) route(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "/v1/users/me" && r.Method == "GET":
+		writeFakeJSON(w, http.StatusOK, map[string]any{"user": s.User})
+	case path == "/v1/projects" && r.Method == "GET":
+		writeFakeJSON(w, http.StatusOK, map[string]any{
+			"projects": fakePage(s.projects, r.URL.Query(), s.MaxPageSize),
+			"Meta":     map[string]int{"total": len(s.projects)},
+		})
+	case path == "/v1/timeentries" && r.Method == "GET":
+		s.handleListTimeEntries(w, r.URL.Query())
+	case path == "/v1/timeentries" && r.Method == "POST":
+		s.handleCreateTimeEntry(w, r)
+	case strings.HasPrefix(path, "/v1/timeentries/") && r.Method == "GET":
+		s.handleGetTimeEntry(w, strings.TrimPrefix(path, "/v1/timeentries/"))
+	case strings.HasPrefix(path, "/v1/timeentries/") && r.Method == "PUT":
+		s.handleUpdateTimeEntry(w, r, strings.TrimPrefix(path, "/v1/timeentries/"))
+	case strings.HasPrefix(path, "/v1/timeentries/") && r.Method == "DELETE":
+		s.handleDeleteTimeEntry(w, strings.TrimPrefix(path, "/v1/timeentries/"))
+	default:
+		writeFakeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("%s %s not found", r.Method, r.URL.Path))
+	}
+}
+
+func (s *FakeServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		if r.PostForm.Get("username") != s.Username || r.PostForm.Get("password") != s.Password {
+			writeFakeError(w, http.StatusUnauthorized, "invalid_grant", "Invalid username or password")
+			return
+		}
+	case "refresh_token":
+		if s.refreshToken == "" || r.PostForm.Get("refresh_token") != s.refreshToken {
+			writeFakeError(w, http.StatusUnauthorized, "invalid_grant", "Invalid refresh token")
+			return
+		}
+	default:
+		writeFakeError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant type")
+		return
+	}
+
+	accessToken, refreshToken := s.issueTokens()
+	writeFakeJSON(w, http.StatusOK, TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    3600,
+	})
+}
+
+func (s *FakeServer) handleListTimeEntries(w http.ResponseWriter, query url.Values) {
+	// like Sails blueprints, loose criteria are ignored once there is a where
+	// clause
+	where := map[string]any{}
+	if query.Has("where") {
+		if err := json.Unmarshal([]byte(query.Get("where")), &where); err != nil {
+			writeFakeError(w, http.StatusBadRequest, "bad_request", "Invalid where clause: "+err.Error())
+			return
+		}
+	} else {
+		for _, attribute := range []string{"user", "day", "project"} {
+			if query.Has(attribute) {
+				where[attribute] = query.Get(attribute)
+			}
+		}
+	}
+
+	var matching []TimeEntry
+	for _, timeEntry := range s.timeEntries {
+		if !matchesFakeWhere(timeEntry, where) {
+			continue
+		}
+		matching = append(matching, s.withElapsedDuration(timeEntry))
+	}
+	sortTimeEntries(matching, strings.Contains(query.Get("sort"), "day DESC"))
+
+	writeFakeJSON(w, http.StatusOK, map[string]any{
+		"timeEntries": fakePage(matching, query, s.MaxPageSize),
+		"Meta":        map[string]int{"total": len(matching)},
+	})
+}
+
+// matchesFakeWhere applies a where clause like
+// {"user":1,"day":{">=":"2026-10-01","<=":"2026-10-07"}} to the user, day and
+// project of a time entry.
+func matchesFakeWhere(timeEntry TimeEntry, where map[string]any) bool {
+	for attribute, condition := range where {
+		var value string
+		switch attribute {
+		case "user":
+			value = strconv.Itoa(timeEntry.UserId)
+		case "day":
+			value = timeEntry.Day
+		case "project":
+			value = strconv.Itoa(timeEntry.ProjectId)
+		default:
+			continue
+		}
+		if !matchesFakeCondition(value, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesFakeCondition compares a value to a condition, which is either the
+// value itself or comparisons like {">=":"2026-10-01"}.
+func matchesFakeCondition(value string, condition any) bool {
+	comparisons, ok := condition.(map[string]any)
+	if !ok {
+		return fmt.Sprint(condition) == value
+	}
+	for operator, operand := range comparisons {
+		comparison := strings.Compare(value, fmt.Sprint(operand))
+		switch operator {
+		case ">=":
+			if comparison < 0 {
+				return false
+			}
+		case ">":
+			if comparison <= 0 {
+				return false
+			}
+		case "<=":
+			if comparison > 0 {
+				return false
+			}
+		case "<":
+			if comparison >= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fakePage applies the limit and skip query parameters to a listing.
+func fakePage[T any](items []T, query url.Values, maxPageSize int) []T {
+	if skip, err := strconv.Atoi(query.Get("skip")); err == nil {
+		items = items[min(skip, len(items)):]
+	}
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || (maxPageSize > 0 && limit > maxPageSize) {
+		limit = maxPageSize
+	}
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items
+}
+
+func (s *FakeServer) handleCreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TimeEntry TimeEntry `json:"timeEntry"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	timeEntry := payload.TimeEntry
+	timeEntry.Id = s.nextId
+	timeEntry.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.nextId++
+	if timeEntry.Running {
+		s.runningSince[timeEntry.Id] = time.Now()
+	}
+	s.timeEntries = append(s.timeEntries, timeEntry)
+
+	writeFakeJSON(w, http.StatusCreated, map[string]any{"timeEntry": timeEntry})
+}
+
+func (s *FakeServer) handleGetTimeEntry(w http.ResponseWriter, id string) {
+	index := slices.IndexFunc(s.timeEntries, func(timeEntry TimeEntry) bool {
+		return strconv.Itoa(timeEntry.Id) == id
+	})
+	if index == -1 {
+		writeFakeError(w, http.StatusNotFound, "not_found", "Time entry "+id+" not found")
+		return
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]any{"timeEntry": s.withElapsedDuration(s.timeEntries[index])})
+}
+
+func (s *FakeServer) handleUpdateTimeEntry(w http.ResponseWriter, r *http.Request, id string) {
+	index := slices.IndexFunc(s.timeEntries, func(timeEntry TimeEntry) bool {
+		return strconv.Itoa(timeEntry.Id) == id
+	})
+	if index == -1 {
+		writeFakeError(w, http.StatusNotFound, "not_found", "Time entry "+id+" not found")
+		return
+	}
+
+	var payload struct {
+		TimeEntry TimeEntry `json:"timeEntry"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	timeEntry := payload.TimeEntry
+	timeEntry.Id = s.timeEntries[index].Id
+	timeEntry.CreatedAt = s.timeEntries[index].CreatedAt
+	if timeEntry.Running {
+		s.runningSince[timeEntry.Id] = time.Now()
+	} else {
+		delete(s.runningSince, timeEntry.Id)
+	}
+	s.timeEntries[index] = timeEntry
+
+	writeFakeJSON(w, http.StatusOK, map[string]any{"timeEntry": timeEntry})
+}
+
+func (s *FakeServer) handleDeleteTimeEntry(w http.ResponseWriter, id string) {
+	index := slices.IndexFunc(s.timeEntries, func(timeEntry TimeEntry) bool {
+		return strconv.Itoa(timeEntry.Id) == id
+	})
+	if index == -1 {
+		writeFakeError(w, http.StatusNotFound, "not_found", "Time entry "+id+" not found")
+		return
+	}
+
+	delete(s.runningSince, s.timeEntries[index].Id)
+	s.timeEntries = slices.Delete(s.timeEntries, index, index+1)
+
+	writeFakeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, code string, message string) {
+	writeFakeJSON(w, status, map[string]any{
+		"status": status,
+		"error":  code,
+		"raw":    message,
+	})
+}
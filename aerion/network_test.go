@@ -0,0 +1,92 @@
+package aerion
+
+import (
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHttpClientTrustsCaBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	untrusted, err := NewHttpClient(NetworkConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := untrusted.Get(server.URL); err == nil {
+		t.Fatal("expected the test server's certificate to be rejected without the CA bundle")
+	}
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, certificate, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewHttpClient(NetworkConfig{CaBundle: caBundle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the CA bundle to be trusted: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHttpClientUsesProxy(t *testing.T) {
+	var proxiedUrl string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedUrl = r.URL.String()
+		w.Write([]byte(`{"user":{"id":1}}`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := NewHttpClient(NetworkConfig{ProxyUrl: proxy.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.Get("http://acme.aerion.invalid/v1/users/me")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if proxiedUrl != "http://acme.aerion.invalid/v1/users/me" {
+		t.Fatalf("expected the request to go through the proxy, got %q", proxiedUrl)
+	}
+}
+
+func TestNewHttpClientRejectsInvalidSettings(t *testing.T) {
+	emptyBundle := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(emptyBundle, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, network := range []NetworkConfig{
+		{CaBundle: emptyBundle},
+		{CaBundle: filepath.Join(t.TempDir(), "missing.pem")},
+		{ClientCert: filepath.Join(t.TempDir(), "missing.pem")},
+		{ProxyUrl: "http://[::1"},
+	} {
+		if _, err := NewHttpClient(network); !errors.Is(err, ErrInvalidNetworkConfig) {
+			t.Errorf("expected ErrInvalidNetworkConfig for %+v, got %v", network, err)
+		}
+	}
+}
+
+func TestNetworkConfigMerge(t *testing.T) {
+	config := NetworkConfig{ProxyUrl: "http://config-proxy", CaBundle: "config.pem"}
+	merged := config.Merge(NetworkConfig{ProxyUrl: "http://flag-proxy"})
+
+	if merged.ProxyUrl != "http://flag-proxy" || merged.CaBundle != "config.pem" {
+		t.Fatalf("unexpected merged config %+v", merged)
+	}
+}
@@ -0,0 +1,79 @@
+package aerion
+
+// DefaultPageSize is the number of items requested per page from listing
+// endpoints.
+const DefaultPageSize = 100
+
+// PageFetcher fetches a single page of a listing endpoint. It returns the
+// items of the page and the total number of items across all pages.
+type PageFetcher[T any] func(limit int, skip int) ([]T, int, error)
+
+// PageIterator walks through a paginated listing endpoint by following
+// limit/skip until the total reported by the API is reached.
+//
+//	it := client.ListProjects()
+//	for it.Next() {
+//		for _, project := range it.Page() { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type PageIterator[T any] struct {
+	PageSize int
+
+	fetch PageFetcher[T]
+	skip  int
+	total int
+	page  []T
+	done  bool
+	err   error
+}
+
+func NewPageIterator[T any](fetch PageFetcher[T]) *PageIterator[T] {
+	return &PageIterator[T]{PageSize: DefaultPageSize, fetch: fetch}
+}
+
+// Next fetches the next page. It returns false when all pages have been read
+// or fetching a page failed.
+func (it *PageIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+
+	page, total, err := it.fetch(it.PageSize, it.skip)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+
+	it.page = page
+	it.total = total
+	it.skip += len(page)
+	if len(page) == 0 || it.skip >= total {
+		it.done = true
+	}
+	return len(page) > 0
+}
+
+// Page returns the items of the current page.
+func (it *PageIterator[T]) Page() []T {
+	return it.page
+}
+
+// Total returns the total number of items reported by the API.
+func (it *PageIterator[T]) Total() int {
+	return it.total
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator[T]) Err() error {
+	return it.err
+}
+
+// All reads the remaining pages and returns their items.
+func (it *PageIterator[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Page()...)
+	}
+	return items, it.Err()
+}
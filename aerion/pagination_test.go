@@ -0,0 +1,99 @@
+package aerion
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPageIteratorFollowsSkipUntilTotal(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	var requestedSkips []int
+
+	it := NewPageIterator(func(limit int, skip int) ([]int, int, error) {
+		requestedSkips = append(requestedSkips, skip)
+		end := min(skip+limit, len(items))
+		return items[skip:end], len(items), nil
+	})
+	it.PageSize = 2
+
+	all, err := it.All()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(all) != fmt.Sprint(items) {
+		t.Fatalf("expected %v, got %v", items, all)
+	}
+	if fmt.Sprint(requestedSkips) != "[0 2 4]" {
+		t.Fatalf("unexpected skips %v", requestedSkips)
+	}
+}
+
+func TestPageIteratorStopsOnEmptyPage(t *testing.T) {
+	calls := 0
+	it := NewPageIterator(func(limit int, skip int) ([]int, int, error) {
+		calls++
+		if skip > 0 {
+			return nil, 10, nil
+		}
+		return []int{1}, 10, nil
+	})
+
+	all, err := it.All()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 1 || calls != 2 {
+		t.Fatalf("expected to stop after an empty page, got %v after %d calls", all, calls)
+	}
+}
+
+func TestPageIteratorReturnsFetchError(t *testing.T) {
+	it := NewPageIterator(func(limit int, skip int) ([]int, int, error) {
+		return nil, 0, fmt.Errorf("boom")
+	})
+
+	if _, err := it.All(); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestClientListsAllPages(t *testing.T) {
+	fake := NewFakeServer()
+	fake.MaxPageSize = 2
+	for i := 0; i < 5; i++ {
+		fake.AddProject(fmt.Sprintf("Project %d", i))
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken})
+	client.UserId = fake.User.Id
+
+	day := time.Now().Format("2006-01-02")
+	for i := 0; i < 5; i++ {
+		_, err := client.CreateTimeEntry(NewTimeEntry{ProjectId: 100, Day: day, Sorting: i + 1, UserId: client.UserId})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects, err := client.GetProjects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 5 || projects[4].Name != "Project 4" {
+		t.Fatalf("expected all 5 projects, got %+v", projects)
+	}
+
+	timeEntries, err := client.GetTimeEntriesForDay(day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(timeEntries) != 5 || timeEntries[4].Sorting != 5 {
+		t.Fatalf("expected all 5 time entries, got %+v", timeEntries)
+	}
+}
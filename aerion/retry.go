@@ -0,0 +1,132 @@
+package aerion
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that failed
+// because of a flaky network or a temporary problem on Aerion's side.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with every
+	// further retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. If a Retry-After header
+	// asks for a longer delay, the request fails instead of being retried
+	// early.
+	MaxDelay time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// delay returns how long to wait before the given retry (starting at 1). It
+// uses exponential backoff with jitter unless the server asked for a longer
+// delay with Retry-After. It reports false if that's longer than MaxDelay, so
+// the request shouldn't be retried at all.
+func (p RetryPolicy) delay(retry int, retryAfter time.Duration) (time.Duration, bool) {
+	if retryAfter > p.MaxDelay {
+		return 0, false
+	}
+
+	backoff := p.BaseDelay << (retry - 1)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	// pick a random delay in [backoff/2, backoff] so parallel clients don't
+	// retry in lockstep
+	if half := int64(backoff / 2); half > 0 {
+		backoff = time.Duration(half + rand.Int64N(half+1))
+	}
+
+	return max(backoff, retryAfter), true
+}
+
+// isIdempotent reports whether a request with the given method can be sent
+// again without changing the outcome.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// isTransientStatus reports whether a response status is worth retrying.
+func isTransientStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// isTransient reports whether err is a network failure or an API error that is
+// likely to go away when the request is retried.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isTransientStatus(apiErr.StatusCode)
+	}
+
+	return IsOffline(err)
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// sendWithRetry sends the request and retries idempotent requests on network
+// failures and transient server errors.
+func (c *Client) sendWithRetry(req *http.Request) (*http.Response, []byte, error) {
+	attempt := req
+	for retry := 1; ; retry++ {
+		resp, body, err := c.send(attempt)
+
+		var retryAfter time.Duration
+		transient := isTransient(err)
+		if err == nil {
+			transient = isTransientStatus(resp.StatusCode)
+			retryAfter = parseRetryAfter(resp.Header)
+		}
+		if !transient || !isIdempotent(req.Method) || retry >= c.Retry.MaxAttempts {
+			return resp, body, err
+		}
+		delay, ok := c.Retry.delay(retry, retryAfter)
+		if !ok {
+			return resp, body, err
+		}
+
+		time.Sleep(delay)
+
+		attempt = req.Clone(req.Context())
+		if req.GetBody != nil {
+			attempt.Body, err = req.GetBody()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
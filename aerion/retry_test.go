@@ -0,0 +1,173 @@
+package aerion
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryTestClient(t *testing.T, fake *FakeServer) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: accessToken, refreshToken: refreshToken})
+	client.UserId = fake.User.Id
+	client.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	return client
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for retry := 1; retry <= 3; retry++ {
+		backoff := policy.BaseDelay << (retry - 1)
+		delay, ok := policy.delay(retry, 0)
+		if !ok || delay < backoff/2 || delay > backoff {
+			t.Errorf("expected retry %d to wait between %s and %s, got %s", retry, backoff/2, backoff, delay)
+		}
+	}
+
+	if delay, ok := policy.delay(10, 0); !ok || delay > policy.MaxDelay {
+		t.Errorf("expected delay to be capped at %s, got %s", policy.MaxDelay, delay)
+	}
+	if delay, ok := policy.delay(1, 700*time.Millisecond); !ok || delay != 700*time.Millisecond {
+		t.Errorf("expected Retry-After to be honored, got %s", delay)
+	}
+	if delay, ok := policy.delay(1, time.Minute); ok {
+		t.Errorf("expected no retry when Retry-After exceeds %s, got %s", policy.MaxDelay, delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "3")
+	if delay := parseRetryAfter(header); delay != 3*time.Second {
+		t.Errorf("expected 3s, got %s", delay)
+	}
+
+	header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if delay := parseRetryAfter(header); delay < 59*time.Minute {
+		t.Errorf("expected about an hour, got %s", delay)
+	}
+
+	if delay := parseRetryAfter(http.Header{}); delay != 0 {
+		t.Errorf("expected no delay, got %s", delay)
+	}
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	fake := NewFakeServer()
+	fake.AddProject("Project One")
+	client := newRetryTestClient(t, fake)
+
+	fake.FailRequests(2, http.StatusServiceUnavailable)
+	projects, err := client.GetProjects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("unexpected projects %+v", projects)
+	}
+}
+
+func TestClientGivesUpAfterMaxAttempts(t *testing.T) {
+	fake := NewFakeServer()
+	client := newRetryTestClient(t, fake)
+
+	fake.FailRequests(3, http.StatusBadGateway)
+	_, err := client.GetProjects()
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsServer() {
+		t.Fatalf("expected a server error, got %v", err)
+	}
+}
+
+func TestClientDoesNotRetryValidationErrors(t *testing.T) {
+	fake := NewFakeServer()
+	client := newRetryTestClient(t, fake)
+
+	fake.FailRequests(1, http.StatusBadRequest)
+	_, err := client.GetProjects()
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsValidation() {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+func TestClientRetriesCreateAfterFailure(t *testing.T) {
+	fake := NewFakeServer()
+	client := newRetryTestClient(t, fake)
+
+	fake.FailRequests(1, http.StatusServiceUnavailable)
+	_, err := client.CreateTimeEntry(NewTimeEntry{ProjectId: 100, Day: "2024-01-02", Sorting: 1, UserId: client.UserId})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeEntries := fake.TimeEntries(); len(timeEntries) != 1 {
+		t.Fatalf("expected 1 time entry, got %+v", timeEntries)
+	}
+}
+
+func TestClientDoesNotDuplicateCreateAfterLostResponse(t *testing.T) {
+	fake := NewFakeServer()
+	client := newRetryTestClient(t, fake)
+
+	fake.DropResponses(1)
+	_, err := client.CreateTimeEntry(NewTimeEntry{ProjectId: 100, Day: "2024-01-02", Sorting: 1, UserId: client.UserId})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeEntries := fake.TimeEntries(); len(timeEntries) != 1 {
+		t.Fatalf("expected 1 time entry, got %+v", timeEntries)
+	}
+}
+
+func TestClientRetriesHangingRequests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if requests.Load() == 1 {
+			// a connection that hangs, like on a flapping VPN
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"user":{"id":42}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	httpClient := server.Client()
+	httpClient.Timeout = 50 * time.Millisecond
+	client := NewClient(server.URL, httpClient, &staticTokenSource{accessToken: "secret"})
+	client.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	user, err := client.GetUser()
+	if err != nil || user.Id != 42 || requests.Load() != 2 {
+		t.Fatalf("expected the hanging request to be retried, got %+v, %v after %d requests", user, err, requests.Load())
+	}
+}
+
+func TestClientDoesNotRetryBeforeRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, server.Client(), &staticTokenSource{accessToken: "secret"})
+	client.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	_, err := client.GetUser()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Minute || requests.Load() != 1 {
+		t.Fatalf("expected to give up right away, got %v after %d requests", err, requests.Load())
+	}
+}
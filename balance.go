@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+func BalanceCommand() {
+	var args struct {
+		Color bool     `cli:"-c, --color, enable colors in the output"`
+		From  string   `cli:"-f, --from, The first day to include (default: Schedule.Start from the config or the start of this month)"`
+		To    string   `cli:"-t, --to, The last day to include (default: today)"`
+		Range []string `cli:"range, The days to include, e.g. last week or 2026-10-01..2026-10-07"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	cfg, _ := ReadConfig()
+	schedule := cfg.Schedule
+	targets, err := schedule.Targets()
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+
+	now := time.Now()
+	today := truncateToDay(now)
+	var start time.Time
+	if schedule.Start != "" {
+		start, err = time.ParseInLocation(DayFormat, schedule.Start, now.Location())
+		if err != nil {
+			fmt.Println(chalk.Red.Color(fmt.Sprintf("invalid Schedule.Start '%s' in the config, expected a date like 2026-01-01", schedule.Start)))
+			os.Exit(ExitInvalidInput)
+		}
+	}
+
+	expr := strings.Join(args.Range, " ")
+	fromExpr := args.From
+	if expr == "" && fromExpr == "" {
+		fromExpr = "this month"
+		if !start.IsZero() {
+			fromExpr = schedule.Start
+		}
+	}
+	from, to, err := parseShowRange(expr, fromExpr, args.To, now)
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+	// future days haven't been worked yet and would only count as undertime
+	if to.After(today) {
+		to = today
+	}
+	if from.After(to) {
+		fmt.Println(chalk.Red.Color("the period lies in the future"))
+		os.Exit(ExitInvalidInput)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	// the balance carried into the period is computed from Schedule.Start on
+	fetchFrom := from
+	if !start.IsZero() && start.Before(from) {
+		fetchFrom = start
+	}
+	timeEntries, err := client.GetTimeEntriesBetween(fetchFrom.Format(DayFormat), to.Format(DayFormat))
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	booked := map[string]int{}
+	for _, timeEntry := range timeEntries {
+		booked[timeEntry.Day] += timeEntry.Duration
+	}
+
+	balance := 0
+	carriesOver := !start.IsZero() && !start.After(from)
+	if carriesOver {
+		balance = hoursToSeconds(schedule.InitialBalance)
+		for day := start; day.Before(from); day = day.AddDate(0, 0, 1) {
+			balance += booked[day.Format(DayFormat)] - targets[day.Weekday()]
+		}
+	}
+
+	fmt.Printf("Balance from %s to %s\n\n", from.Format(DayFormat), to.Format(DayFormat))
+	fmt.Printf("%-16s | %8s | %8s | %9s | %9s\n", "day", "target", "booked", "diff", "balance")
+	if carriesOver {
+		printBalanceLine("carried over", -1, -1, 0, balance, args.Color)
+	}
+
+	var totalTarget, totalBooked int
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		target := targets[day.Weekday()]
+		if !start.IsZero() && day.Before(start) {
+			target = 0
+		}
+		if day.Equal(start) && !carriesOver {
+			balance += hoursToSeconds(schedule.InitialBalance)
+		}
+		bookedSeconds := booked[day.Format(DayFormat)]
+		if target == 0 && bookedSeconds == 0 {
+			continue
+		}
+
+		totalTarget += target
+		totalBooked += bookedSeconds
+		balance += bookedSeconds - target
+		printBalanceLine(day.Format("Mon, 2006-01-02"), target, bookedSeconds, bookedSeconds-target, balance, args.Color)
+	}
+
+	fmt.Println()
+	fmt.Printf("%-10s |    %s\n", "target", formatHoursMinutes(totalTarget))
+	fmt.Printf("%-10s |    %s\n", "booked", formatHoursMinutes(totalBooked))
+	fmt.Printf("%-10s |   %s\n", "overtime", colorBalance(formatSignedHoursMinutes(balance), balance, args.Color))
+}
+
+// printBalanceLine prints one row of the balance table. A negative target or
+// booked duration leaves its cell empty.
+func printBalanceLine(label string, target int, booked int, diff int, balance int, color bool) {
+	cell := func(seconds int) string {
+		if seconds < 0 {
+			return ""
+		}
+		return formatHoursMinutes(seconds)
+	}
+	diffString := fmt.Sprintf("%9s", "")
+	if target >= 0 {
+		diffString = colorBalance(fmt.Sprintf("%9s", formatSignedHoursMinutes(diff)), diff, color)
+	}
+
+	fmt.Printf("%-16s | %8s | %8s | %s | %s\n", label, cell(target), cell(booked), diffString, colorBalance(fmt.Sprintf("%9s", formatSignedHoursMinutes(balance)), balance, color))
+}
+
+func colorBalance(text string, seconds int, color bool) string {
+	if !color || seconds == 0 {
+		return text
+	}
+	if seconds < 0 {
+		return chalk.Red.Color(text)
+	}
+	return chalk.Green.Color(text)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+// Status bars the bar command writes for.
+const (
+	BarWaybar   = "waybar"
+	BarI3blocks = "i3blocks"
+	BarPolybar  = "polybar"
+)
+
+// CSS classes of the waybar module, which also pick the colors of the others.
+const (
+	BarClassRunning    = "running"
+	BarClassIdle       = "idle"
+	BarClassOverTarget = "over-target"
+)
+
+var barColors = map[string]string{
+	BarClassRunning:    "#50FA7B",
+	BarClassOverTarget: "#FFB86C",
+}
+
+// barStatus is the status shown in the bar, in the fields of waybar's JSON.
+type barStatus struct {
+	Text    string `json:"text"`
+	Tooltip string `json:"tooltip"`
+	Class   string `json:"class"`
+}
+
+// newBarStatus describes the prompt data, given today's target seconds. Once
+// the target is exceeded, the class is over-target whether a timer runs or not.
+func newBarStatus(data PromptData, target int) barStatus {
+	var status barStatus
+	var tooltip []string
+	if data.Running {
+		status.Text = fmt.Sprintf("⏱ %s %s · %s", data.Alias, formatClock(data.Elapsed), formatClock(data.Total))
+		status.Class = BarClassRunning
+		tooltip = append(tooltip, data.ProjectName)
+		if data.Comment != "" {
+			tooltip = append(tooltip, data.Comment)
+		}
+	} else {
+		status.Text = "⏸ " + formatClock(data.Total)
+		status.Class = BarClassIdle
+		tooltip = append(tooltip, "No timer running")
+	}
+
+	if target > 0 {
+		tooltip = append(tooltip, fmt.Sprintf("Today: %s of %s", formatClock(data.Total), formatClock(target)))
+		if data.Total > target {
+			status.Class = BarClassOverTarget
+		}
+	} else {
+		tooltip = append(tooltip, "Today: "+formatClock(data.Total))
+	}
+	if data.Stale && !data.UpdatedAt.IsZero() {
+		tooltip = append(tooltip, "Last updated "+data.UpdatedAt.Format("15:04"))
+	}
+
+	status.Tooltip = strings.Join(tooltip, "\n")
+	return status
+}
+
+// write prints the status in the format of the given bar: a line of JSON for
+// waybar, the full_text, short_text and color lines for i3blocks, and a line
+// with color tags for polybar.
+func (s barStatus) write(w io.Writer, bar string) error {
+	color := barColors[s.Class]
+	switch bar {
+	case BarWaybar:
+		// waybar renders text and tooltip as Pango markup
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(barStatus{
+			Text:    html.EscapeString(s.Text),
+			Tooltip: html.EscapeString(s.Tooltip),
+			Class:   s.Class,
+		})
+	case BarI3blocks:
+		fmt.Fprintln(w, s.Text)
+		fmt.Fprintln(w, s.Text)
+		if color != "" {
+			fmt.Fprintln(w, color)
+		}
+		return nil
+	case BarPolybar:
+		if color == "" {
+			_, err := fmt.Fprintln(w, s.Text)
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%%{F%s}%s%%{F-}\n", color, s.Text)
+		return err
+	}
+	return fmt.Errorf("unknown bar '%s', use waybar, i3blocks or polybar", bar)
+}
+
+// toggleTimer stops the running timer, or resumes the last one when none is
+// running. Its output would end up in the bar, so only errors are reported,
+// on stderr.
+func toggleTimer(running bool) {
+	entry := JournalEntry{Operation: JournalResume, At: time.Now()}
+	if running {
+		entry.Operation = JournalStop
+	}
+
+	_, err := withoutStdout(func() (bool, error) {
+		client := loggedInClient()
+		if client == nil {
+			return false, nil
+		}
+		return tryRunOrRecord(client, entry)
+	})
+	if err != nil {
+		message, _ := describeApiError(err)
+		fmt.Fprintln(os.Stderr, message)
+	}
+}
+
+func BarCommand() {
+	var args struct {
+		Bar     string        `cli:"bar, Status bar to write for: waybar, i3blocks or polybar (default: waybar)"`
+		Toggle  bool          `cli:"-t, --toggle, Stop the running timer or resume the last one first, for click actions"`
+		Stale   time.Duration `cli:"--stale, Age after which the state counts as stale, e.g. 5m" default:"5m"`
+		Refresh bool          `cli:"-r, --refresh, Fetch today's entries in the background when the state is stale"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	bar := strings.ToLower(args.Bar)
+	switch bar {
+	case "":
+		bar = BarWaybar
+	case BarWaybar, BarI3blocks, BarPolybar:
+	default:
+		fmt.Fprintln(os.Stderr, chalk.Red.Color(fmt.Sprintf("Unknown bar '%s', use waybar, i3blocks or polybar", args.Bar)))
+		os.Exit(ExitInvalidInput)
+	}
+
+	state, _ := ReadState()
+	data := newPromptData(state, time.Now(), args.Stale)
+	// i3blocks runs the block again on clicks, telling the button in BLOCK_BUTTON
+	if args.Toggle || (bar == BarI3blocks && os.Getenv("BLOCK_BUTTON") == "1") {
+		toggleTimer(data.Running)
+		state, _ = ReadState()
+		data = newPromptData(state, time.Now(), args.Stale)
+	} else if data.Stale && args.Refresh {
+		startBackgroundRefresh()
+	}
+
+	cfg, _ := ReadConfig()
+	target, err := cfg.Schedule.TargetSeconds(time.Now())
+	if err != nil {
+		target = 0
+	}
+
+	err = newBarStatus(data, target).write(os.Stdout, bar)
+	if err != nil {
+		panic(err)
+	}
+}
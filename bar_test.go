@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewBarStatus(t *testing.T) {
+	data := PromptData{Running: true, Alias: "p1", ProjectName: "Project One", Comment: "- Fix <login>", Elapsed: 4500, Total: 6 * 3600}
+
+	status := newBarStatus(data, 8*3600)
+	expected := barStatus{Text: "⏱ p1 1:15 · 6:00", Tooltip: "Project One\n- Fix <login>\nToday: 6:00 of 8:00", Class: BarClassRunning}
+	if status != expected {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if status := newBarStatus(data, 5*3600); status.Class != BarClassOverTarget {
+		t.Fatalf("expected over-target, got %+v", status)
+	}
+
+	status = newBarStatus(PromptData{Total: 1800}, 0)
+	expected = barStatus{Text: "⏸ 0:30", Tooltip: "No timer running\nToday: 0:30", Class: BarClassIdle}
+	if status != expected {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestBarStatusWrite(t *testing.T) {
+	status := barStatus{Text: "⏱ p1 1:15 · 6:00", Tooltip: "Project One\n- Fix <login>", Class: BarClassRunning}
+	tests := map[string]string{
+		BarWaybar:   `{"text":"⏱ p1 1:15 · 6:00","tooltip":"Project One\n- Fix &lt;login&gt;","class":"running"}` + "\n",
+		BarI3blocks: "⏱ p1 1:15 · 6:00\n⏱ p1 1:15 · 6:00\n#50FA7B\n",
+		BarPolybar:  "%{F#50FA7B}⏱ p1 1:15 · 6:00%{F-}\n",
+	}
+	for bar, expected := range tests {
+		var out bytes.Buffer
+		err := status.write(&out, bar)
+		if err != nil || out.String() != expected {
+			t.Errorf("write(%s) = %q, %v, expected %q", bar, out.String(), err, expected)
+		}
+	}
+
+	if err := status.write(&bytes.Buffer{}, "dzen"); err == nil {
+		t.Error("expected an error for an unknown bar")
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"regexp"
@@ -12,35 +14,202 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fischeversenker/aerion-cli/aerion"
 	"github.com/jxskiss/mcli"
 	"github.com/ttacon/chalk"
 	"golang.org/x/term"
 )
 
 func main() {
-	mcli.Add("login", LoginCommand, "Login to Aerion")
-	mcli.Add("start", StartCommand, "Starts/Resumes a time entry. Needs a project alias as argument. Optionally, you can provide a comment that will be appeneded to any existing comment.")
-	mcli.Add("stop", StopCommand, "Stops any running time entries")
-	mcli.Add("today", TodayCommand, "Lists today's time entries")
-	mcli.AddAlias("status", "today")
-	mcli.Add("yesterday", YesterdayCommand, "Lists yesterday's time entries")
+	newApp().Run()
+}
+
+// GlobalFlags are accepted by all commands.
+type GlobalFlags struct {
+	Debug      bool   `cli:"--debug, Trace API requests and responses to stderr (secrets are redacted)" env:"AERION_DEBUG"`
+	Proxy      string `cli:"--proxy, Proxy URL for all requests, overrides Network.ProxyUrl from the config" env:"AERION_PROXY"`
+	CaBundle   string `cli:"--ca-bundle, PEM file with additional root CAs, overrides Network.CaBundle from the config"`
+	ClientCert string `cli:"--client-cert, PEM file with a client certificate, overrides Network.ClientCert from the config"`
+	ClientKey  string `cli:"--client-key, PEM file with the client certificate's key, overrides Network.ClientKey from the config"`
+	Output     string `cli:"-o, --output, Output format of listings: text, json, csv or tsv" default:"text" env:"AERION_OUTPUT"`
+	Format     string `cli:"--format, Go template for each listed entry or project, e.g. {{.Alias}} {{.Duration | hm}} {{.Comment}}" env:"AERION_FORMAT"`
+}
 
-	mcli.Add("version", func() { fmt.Println("v0.3.1") }, "Prints the version of aerion CLI")
+// network returns the network settings given as flags.
+func (f *GlobalFlags) network() aerion.NetworkConfig {
+	return aerion.NetworkConfig{
+		ProxyUrl:   f.Proxy,
+		CaBundle:   f.CaBundle,
+		ClientCert: f.ClientCert,
+		ClientKey:  f.ClientKey,
+	}
+}
 
-	mcli.AddGroup("projects", "Lists projects and assign aliases to your active projects")
-	mcli.Add("projects list", ProjectsListCommand, "Lists all active projects")
-	mcli.Add("projects alias", ProjectAliasCommand, "Lists the known aliases or sets new ones. Use the \"projects list\" command to figure out the ID of your project.")
+var globalFlags = &GlobalFlags{}
+
+func newApp() *mcli.App {
+	app := mcli.NewApp()
+	globalFlags = &GlobalFlags{}
+	app.SetGlobalFlags(globalFlags)
+	app.Add("login", LoginCommand, "Login to Aerion")
+	app.Add("start", StartCommand, "Starts/Resumes a time entry. Needs a project alias as argument. Optionally, you can provide a comment that will be appeneded to any existing comment. Without alias, it resumes the last stopped time entry.")
+	app.Add("resume", ResumeCommand, "Resumes the last stopped time entry of today, or continues the last one of the previous working day")
+	app.Add("add", AddCommand, "Books a finished time entry with a given duration, e.g. for a meeting you forgot to track. Use --day to book it on another day.")
+	app.Add("edit", EditCommand, "Changes the duration, comment, project, task or day of a time entry, selected by its position in the listing of the day or by ID")
+	app.Add("delete", DeleteCommand, "Deletes a time entry, selected by its position in the listing of the day or by ID, or all empty entries of a day with --empty")
+	app.Add("undo", UndoCommand, "Reverts the changes of the last start, stop, resume, note, add, edit or delete command")
+	app.Add("stop", StopCommand, "Stops any running time entries")
+	app.Add("note", NoteCommand, "Adds a bullet to the comment of the running time entry, or replaces or removes the last one")
+	app.Add("sync", SyncCommand, "Sends start/stop operations that were recorded while Aerion was unreachable")
+	app.Add("today", TodayCommand, "Lists today's time entries")
+	app.AddAlias("status", "today")
+	app.Add("yesterday", YesterdayCommand, "Lists yesterday's time entries")
+	app.Add("show", ShowCommand, "Lists the time entries of any day or range of days, e.g. 'show last friday' or 'show last week'")
+	app.Add("standup", StandupCommand, "Writes the comments of the previous working day and today as Markdown grouped by project, ready to paste into Slack or Teams")
+	app.Add("week", WeekCommand, "Shows a project × weekday matrix of the hours booked in a week")
+	app.Add("prompt", PromptCommand, "Prints the running project and its time for shell prompts and status lines, without calling the API")
+	app.Add("bar", BarCommand, "Prints the running project, its time and today's total for waybar, i3blocks or polybar. With --toggle, clicks stop or resume the timer.")
+	app.Add("balance", BalanceCommand, "Compares the booked hours to the target hours of your work schedule and shows your overtime")
+
+	app.Add("version", func() { fmt.Println("v0.3.1") }, "Prints the version of aerion CLI")
+
+	app.AddGroup("projects", "Lists projects and assign aliases to your active projects")
+	app.Add("projects list", ProjectsListCommand, "Lists all active projects")
+	app.Add("projects alias", ProjectAliasCommand, "Lists the known aliases or sets new ones. Use the \"projects list\" command to figure out the ID of your project.")
+
+	app.AddHidden("dev fake-server", DevFakeServerCommand, "Runs an in-memory fake Aerion server for offline development")
 
 	// Enable shell auto-completion, see `program completion -h` for help.
-	// mcli.AddCompletion()
-	mcli.AddHelp()
+	// app.AddCompletion()
+	app.AddHelp()
 
-	mcli.Run()
+	return app
+}
+
+// newClient creates the API client used by all commands. Tests replace it to
+// point the commands at a fake server.
+var newClient = NewAerionClientFromConfig
+
+// newCommandClient creates the API client for a command, honoring the global
+// flags. Commands must parse their arguments before calling it.
+func newCommandClient() (*AerionClient, error) {
+	client, err := newClient(globalFlags.network())
+	if err != nil {
+		return nil, err
+	}
+	if globalFlags.Debug {
+		client.Debug = os.Stderr
+	}
+	return client, nil
+}
+
+// exitOnInvalidNetworkConfig exits if the proxy, CA bundle or client
+// certificate given in the config or the flags can't be used.
+func exitOnInvalidNetworkConfig(err error) {
+	if errors.Is(err, aerion.ErrInvalidNetworkConfig) {
+		fmt.Fprintln(os.Stderr, chalk.Red.Color(err.Error()))
+		os.Exit(ExitError)
+	}
+}
+
+// loggedInClient returns an API client for the stored login. If there is no
+// usable login, it asks the user to login first and exits with
+// ExitLoginRequired. Other failures, e.g. of the token endpoint, exit like
+// failed API calls.
+func loggedInClient() *AerionClient {
+	client, err := newCommandClient()
+	exitOnInvalidNetworkConfig(err)
+	if err != nil {
+		// without company or config, the user has never logged in
+		err = fmt.Errorf("%w: %w", aerion.ErrLoginRequired, err)
+	} else {
+		err = client.EnsureLoggedIn()
+	}
+	// when Aerion is unreachable, commands can still record offline operations
+	if err == nil || aerion.IsOffline(err) {
+		return client
+	}
+	if isLoginRequired(err) {
+		fmt.Fprintln(os.Stderr, chalk.Yellow.Color("Please login first using the 'login' command"))
+		os.Exit(ExitLoginRequired)
+	}
+	exitOnApiError(err)
+	return nil
+}
+
+// isLoginRequired reports whether err means the stored login can't be used
+// anymore, as opposed to Aerion failing to check it. The token endpoint
+// rejects revoked refresh tokens with invalid_grant.
+func isLoginRequired(err error) bool {
+	var apiErr *aerion.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsAuth() || apiErr.Code == "invalid_grant"
+	}
+	return errors.Is(err, aerion.ErrLoginRequired)
+}
+
+// Exit codes of failed commands, so scripts can tell failures apart.
+const (
+	ExitError         = 1
+	ExitLoginRequired = 3
+	ExitNotFound      = 4
+	ExitInvalidInput  = 5
+	ExitServerError   = 6
+	ExitNetworkError  = 7
+)
+
+// exitOnApiError stops the command after a failed API call with a message
+// for the user and an exit code matching the kind of failure.
+func exitOnApiError(err error) {
+	message, exitCode := describeApiError(err)
+	fmt.Fprintln(os.Stderr, chalk.Red.Color(message))
+	os.Exit(exitCode)
+}
+
+func describeApiError(err error) (string, int) {
+	if errors.Is(err, aerion.ErrLoginRequired) {
+		return "Your session has expired. Please login again using the 'login' command", ExitLoginRequired
+	}
+	if errors.Is(err, ErrTimeEntryNotFound) {
+		return err.Error(), ExitNotFound
+	}
+
+	var apiErr *aerion.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.IsAuth():
+			return fmt.Sprintf("You are not allowed to do this: %s", apiErr), ExitLoginRequired
+		case apiErr.IsNotFound():
+			return fmt.Sprintf("Aerion couldn't find what you were looking for: %s", apiErr), ExitNotFound
+		case apiErr.IsValidation():
+			return fmt.Sprintf("Aerion rejected the request: %s", apiErr), ExitInvalidInput
+		case apiErr.IsServer():
+			return fmt.Sprintf("Aerion is having trouble right now, please try again later: %s", apiErr), ExitServerError
+		}
+		return apiErr.Error(), ExitError
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Sprintf("Couldn't reach Aerion: %s", urlErr.Err), ExitNetworkError
+	}
+
+	return err.Error(), ExitError
 }
 
 func LoginCommand() {
+	_, err := mcli.Parse(nil)
+	if err != nil {
+		panic(err)
+	}
+
 	reader := bufio.NewReader(os.Stdin)
-	loggedInUser, err := GetUser()
+	var loggedInUser aerion.User
+	client, err := newCommandClient()
+	exitOnInvalidNetworkConfig(err)
+	if err == nil {
+		loggedInUser, err = client.GetUser()
+	}
 	// fixme: user is always seen as logged in (as long as there is content in config file?)
 	if err == nil {
 		fmt.Printf("You are already logged in as \"%s\".\n", loggedInUser.Email)
@@ -54,12 +223,17 @@ func LoginCommand() {
 		}
 	}
 
-	fmt.Print("Enter company prefix (the \"acme\" in \"acme.aerion.app\"): ")
+	fmt.Print("Enter company prefix (the \"acme\" in \"acme.aerion.app\") or the full URL of your Aerion instance: ")
 	companyName, err := reader.ReadString('\n')
 	if err != nil {
 		panic(err)
 	}
-	StoreCompany(strings.TrimSpace(companyName))
+	company, baseUrl := ParseCompanyInput(companyName)
+	if baseUrl != "" {
+		StoreBaseUrl(baseUrl)
+	} else {
+		StoreCompany(company)
+	}
 
 	fmt.Print("Enter Username: ")
 	username, err := reader.ReadString('\n')
@@ -76,14 +250,25 @@ func LoginCommand() {
 	fmt.Println()
 	fmt.Println()
 
-	err = LoginWithPassword(strings.TrimSpace(username), string(bytePassword))
+	client, err = newCommandClient()
+	exitOnInvalidNetworkConfig(err)
 	if err != nil {
-		panic(err)
+		exitOnApiError(err)
 	}
 
-	user, err := GetUser()
+	err = client.LoginWithPassword(strings.TrimSpace(username), string(bytePassword))
+	var apiErr *aerion.APIError
+	if errors.As(err, &apiErr) && apiErr.IsAuth() {
+		fmt.Fprintln(os.Stderr, chalk.Red.Color("Invalid username or password"))
+		os.Exit(ExitLoginRequired)
+	}
 	if err != nil {
-		panic(err)
+		exitOnApiError(err)
+	}
+
+	user, err := client.GetUser()
+	if err != nil {
+		exitOnApiError(err)
 	}
 
 	StoreUserId(user.Id)
@@ -91,20 +276,27 @@ func LoginCommand() {
 }
 
 func ProjectsListCommand() {
-	err := EnsureLoggedIn()
+	_, err := mcli.Parse(nil)
 	if err != nil {
-		fmt.Println(chalk.Yellow.Color("Please login first using the 'login' command"))
+		panic(err)
+	}
+
+	client := loggedInClient()
+	if client == nil {
 		return
 	}
 
-	projects, err := GetProjects()
+	projects, err := client.GetProjects()
 
 	if err != nil {
-		panic(err)
+		exitOnApiError(err)
 	}
 
-	for _, project := range projects {
-		fmt.Printf("%-8d %s\n", project.Id, project.Name)
+	format := outputFormat()
+	if format == OutputText {
+		for _, project := range projects {
+			fmt.Printf("%-8d %s\n", project.Id, project.Name)
+		}
 	}
 
 	// add to config
@@ -113,42 +305,66 @@ func ProjectsListCommand() {
 		cfg.Projects = make(map[string]ProjectConfig)
 	}
 
+	var projectConfigs []ProjectConfig
 	for _, project := range projects {
 		currentConfig := cfg.Projects[strconv.Itoa(project.Id)]
-		cfg.Projects[strconv.Itoa(project.Id)] = ProjectConfig{
+		projectConfig := ProjectConfig{
 			Id:            project.Id,
 			Name:          project.Name,
 			Alias:         currentConfig.Alias,
 			DefaultTaskId: currentConfig.DefaultTaskId,
 		}
+		cfg.Projects[strconv.Itoa(project.Id)] = projectConfig
+		projectConfigs = append(projectConfigs, projectConfig)
 	}
 
 	WriteConfig(cfg)
-}
 
-func ProjectAliasCommand() {
-	err := EnsureLoggedIn()
-	if err != nil {
-		fmt.Println(chalk.Yellow.Color("Please login first using the 'login' command"))
-		return
+	if format != OutputText {
+		err = writeProjects(os.Stdout, format, projectConfigs)
+		if err != nil {
+			panic(err)
+		}
 	}
+}
 
+func ProjectAliasCommand() {
 	var args struct {
 		ProjectId string `cli:"id, The ID of the project (optional)"`
 		Alias     string `cli:"alias, The alias of the project (optional)"`
 	}
-	_, err = mcli.Parse(&args)
+	_, err := mcli.Parse(&args)
 	if err != nil {
 		panic(err)
 	}
 
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
 	cfg, _ := ReadConfig()
 	if (args.ProjectId == "") && (args.Alias == "") {
+		var aliased []ProjectConfig
 		for _, project := range cfg.Projects {
 			if project.Alias != "" {
-				fmt.Printf("%-10s %-20s (ID: %d)\n", project.Alias, project.Name, project.Id)
+				aliased = append(aliased, project)
 			}
 		}
+		slices.SortFunc(aliased, func(a, b ProjectConfig) int {
+			return strings.Compare(a.Alias, b.Alias)
+		})
+
+		if format := outputFormat(); format != OutputText {
+			err = writeProjects(os.Stdout, format, aliased)
+			if err != nil {
+				panic(err)
+			}
+			return
+		}
+		for _, project := range aliased {
+			fmt.Printf("%-10s %-20s (ID: %d)\n", project.Alias, project.Name, project.Id)
+		}
 		return
 	}
 
@@ -166,7 +382,7 @@ func ProjectAliasCommand() {
 		project = ProjectConfig{}
 	}
 
-	lastTimeEntryForProject, err := GetLastTimeEntryForProject(project.Id)
+	lastTimeEntryForProject, err := client.GetLastTimeEntryForProject(project.Id)
 
 	if err != nil {
 		fmt.Printf("%sCouldn't determine your default Task ID for project '%s'%s.\n", chalk.Red, project.Name, chalk.Reset)
@@ -184,168 +400,206 @@ func ProjectAliasCommand() {
 }
 
 func StartCommand() {
-	err := EnsureLoggedIn()
-	if err != nil {
-		fmt.Println(chalk.Yellow.Color("Please login first using the 'login' command"))
-		return
-	}
-
 	var args struct {
-		Alias   string `cli:"#R, alias, The alias of the project"`
+		Alias   string `cli:"alias, The alias of the project (default: resume the last stopped entry)"`
 		Comment string `cli:"comment, The comment for the time entry"`
 		Amend   bool   `cli:"-amend, Add to the previous entry"`
 	}
-	_, err = mcli.Parse(&args)
+	_, err := mcli.Parse(&args)
 	if err != nil {
 		panic(err)
 	}
 
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	if args.Alias == "" {
+		runOrRecord(client, JournalEntry{
+			Operation: JournalResume,
+			At:        time.Now(),
+		})
+		return
+	}
+
 	if args.Comment == "" {
 		args.Amend = true
 	}
 
-	timeEntries, err := GetTodaysTimeEntries()
+	if _, ok := findProjectByAlias(args.Alias); !ok {
+		fmt.Printf("Project alias %s'%s'%s not found 😱\nRun the %s'help projects alias'%s command to learn how to set an alias.\n", chalk.Red, args.Alias, chalk.Reset, chalk.Cyan, chalk.Reset)
+		os.Exit(1)
+	}
 
+	runOrRecord(client, JournalEntry{
+		Operation: JournalStart,
+		Alias:     args.Alias,
+		Comment:   args.Comment,
+		Amend:     args.Amend,
+		At:        time.Now(),
+	})
+}
+
+// startProject starts, resumes or amends the time entry of the given project.
+// since is the time the user asked for it, which lies in the past when offline
+// operations are replayed. The time passed since then is added to the started
+// entry and taken off any entry that gets stopped.
+func startProject(client *AerionClient, targetedProject ProjectConfig, comment string, amend bool, since time.Time) error {
+	day := since.Format("2006-01-02")
+	elapsed := int(time.Since(since).Seconds())
+
+	timeEntries, err := client.GetTimeEntriesForDay(day)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	slices.Reverse(timeEntries)
 
-	cfg, _ := ReadConfig()
-	projectConfigs := cfg.Projects
-	var targetedProject ProjectConfig
-	for _, project := range projectConfigs {
-		if project.Alias == args.Alias {
-			targetedProject = project
-			break
+	if !amend {
+		err := stopTimeEntries(client, since)
+		if err != nil {
+			return err
 		}
+		created, err := client.CreateTimeEntry(aerion.NewTimeEntry{
+			ProjectId:    targetedProject.Id,
+			Day:          day,
+			Duration:     elapsed,
+			Sorting:      nextSorting(timeEntries),
+			Running:      true,
+			Comment:      comment,
+			TaskId:       targetedProject.DefaultTaskId,
+			TrackingType: "WORK",
+			UserId:       client.UserId,
+		})
+		if err != nil {
+			fmt.Println("Error creating new time entry:")
+			return err
+		}
+		client.Action.Created(created)
+
+		fmt.Printf("Started new time entry for %s%s%s\n", chalk.Green, targetedProject.Alias, chalk.Reset)
+		return nil
 	}
-	if targetedProject.Id == 0 {
-		fmt.Printf("Project alias %s'%s'%s not found 😱\nRun the %s'help projects alias'%s command to learn how to set an alias.\n", chalk.Red, args.Alias, chalk.Reset, chalk.Cyan, chalk.Reset)
-		os.Exit(1)
-	}
-	if args.Amend {
-		resumedExistingTimeEntry := false
-		wasRunningAlready := false
-		for _, timeEntry := range timeEntries {
-			if timeEntry.Running {
-				if targetedProject.Id == timeEntry.ProjectId {
-					fmt.Printf("%s%s%s is running already\n", chalk.Green, targetedProject.Alias, chalk.Reset)
-					if args.Comment != "" {
-						if timeEntry.Comment == "" {
-							timeEntry.Comment = "- " + args.Comment
-						} else {
-							timeEntry.Comment = timeEntry.Comment + "\n- " + args.Comment
-						}
-						err := UpdateTimeEntry(timeEntry)
-						if err != nil {
-							panic(err)
-						}
-						fmt.Printf("Added comment '%s'\n", args.Comment)
-					}
-					wasRunningAlready = true
-				} else {
-					// wrong project is running, stop it
-					timeEntry.Running = false
-					err := UpdateTimeEntry(timeEntry)
+
+	resumedExistingTimeEntry := false
+	wasRunningAlready := false
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Running {
+			if targetedProject.Id == timeEntry.ProjectId {
+				fmt.Printf("%s%s%s is running already\n", chalk.Green, targetedProject.Alias, chalk.Reset)
+				if comment != "" {
+					client.Action.Updated(timeEntry)
+					timeEntry.Comment = appendComment(timeEntry.Comment, comment)
+					err := client.UpdateTimeEntry(timeEntry)
 					if err != nil {
-						panic(err)
+						return err
 					}
+					fmt.Printf("Added comment '%s'\n", comment)
 				}
+				wasRunningAlready = true
 			} else {
-				if targetedProject.Id == timeEntry.ProjectId {
-					// not running, resume it
-					timeEntry.Running = true
-					if args.Comment != "" {
-						if timeEntry.Comment == "" {
-							timeEntry.Comment = "- " + args.Comment
-						} else {
-							timeEntry.Comment = timeEntry.Comment + "\n- " + args.Comment
-						}
-					}
-					err := UpdateTimeEntry(timeEntry)
-					if err != nil {
-						panic(err)
-					}
-					fmt.Printf("Resumed existing time entry for %s%s%s\n", chalk.Green, args.Alias, chalk.Reset)
-					resumedExistingTimeEntry = true
-					break
+				// wrong project is running, stop it
+				client.Action.Updated(timeEntry)
+				timeEntry.Running = false
+				timeEntry.Duration = max(timeEntry.Duration-elapsed, 0)
+				err := client.UpdateTimeEntry(timeEntry)
+				if err != nil {
+					return err
 				}
+				recordStoppedTimeEntry(timeEntry.Id)
 			}
-		}
-		if !resumedExistingTimeEntry && !wasRunningAlready {
-			// start a new time entry
-			projectId := targetedProject.Id
-			today := time.Now().Format("2006-01-02")
-			var comment string
-			if args.Comment != "" {
-				comment = "- " + args.Comment
-			}
-			err := CreateTimeEntry(NewTimeEntry{
-				ProjectId:    projectId,
-				Day:          today,
-				Duration:     0,
-				Sorting:      len(timeEntries) + 1,
-				Running:      true,
-				Comment:      comment,
-				TaskId:       targetedProject.DefaultTaskId,
-				TrackingType: "WORK",
-				UserId:       GetUserIdFromConfig(),
-			})
-			if err != nil {
-				fmt.Println("Error creating new time entry:")
-
-				fmt.Printf("%s%s%s\n", chalk.Red, err, chalk.Reset)
-				os.Exit(1)
+		} else {
+			if targetedProject.Id == timeEntry.ProjectId {
+				// not running, resume it
+				client.Action.Updated(timeEntry)
+				timeEntry.Running = true
+				timeEntry.Duration += elapsed
+				if comment != "" {
+					timeEntry.Comment = appendComment(timeEntry.Comment, comment)
+				}
+				err := client.UpdateTimeEntry(timeEntry)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Resumed existing time entry for %s%s%s\n", chalk.Green, targetedProject.Alias, chalk.Reset)
+				resumedExistingTimeEntry = true
+				break
 			}
-
-			fmt.Printf("Started new time entry for %s%s%s\n", chalk.Green, args.Alias, chalk.Reset)
 		}
-	} else {
-		StopCommand()
-		today := time.Now().Format("2006-01-02")
-		err := CreateTimeEntry(NewTimeEntry{
+	}
+	if !resumedExistingTimeEntry && !wasRunningAlready {
+		// start a new time entry
+		var newComment string
+		if comment != "" {
+			newComment = "- " + comment
+		}
+		created, err := client.CreateTimeEntry(aerion.NewTimeEntry{
 			ProjectId:    targetedProject.Id,
-			Day:          today,
-			Duration:     0,
-			Sorting:      len(timeEntries) + 1,
+			Day:          day,
+			Duration:     elapsed,
+			Sorting:      nextSorting(timeEntries),
 			Running:      true,
-			Comment:      args.Comment,
+			Comment:      newComment,
 			TaskId:       targetedProject.DefaultTaskId,
 			TrackingType: "WORK",
-			UserId:       GetUserIdFromConfig(),
+			UserId:       client.UserId,
 		})
 		if err != nil {
 			fmt.Println("Error creating new time entry:")
-
-			fmt.Printf("%s%s%s\n", chalk.Red, err, chalk.Reset)
-			os.Exit(1)
+			return err
 		}
+		client.Action.Created(created)
 
-		fmt.Printf("Started new time entry for %s%s%s\n", chalk.Green, args.Alias, chalk.Reset)
+		fmt.Printf("Started new time entry for %s%s%s\n", chalk.Green, targetedProject.Alias, chalk.Reset)
 	}
 
+	return nil
+}
+
+// appendComment adds a "- " bullet to an existing comment.
+func appendComment(existing string, comment string) string {
+	if existing == "" {
+		return "- " + comment
+	}
+	return existing + "\n- " + comment
 }
 
 func StopCommand() {
-	err := EnsureLoggedIn()
+	_, err := mcli.Parse(nil)
 	if err != nil {
-		fmt.Println(chalk.Yellow.Color("Please login first using the 'login' command"))
+		panic(err)
+	}
+
+	client := loggedInClient()
+	if client == nil {
 		return
 	}
 
-	timeEntries, err := GetTodaysTimeEntries()
+	runOrRecord(client, JournalEntry{
+		Operation: JournalStop,
+		At:        time.Now(),
+	})
+}
+
+// stopTimeEntries stops all running time entries of the given time's day. If
+// that time lies in the past, the time passed since then is taken off the
+// stopped entries.
+func stopTimeEntries(client *AerionClient, at time.Time) error {
+	elapsed := int(time.Since(at).Seconds())
+	timeEntries, err := client.GetTimeEntriesForDay(at.Format("2006-01-02"))
 
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	cfg, _ := ReadConfig()
 	projectConfigs := cfg.Projects
 	for _, timeEntry := range timeEntries {
 		if timeEntry.Running {
+			client.Action.Updated(timeEntry)
 			timeEntry.Running = false
+			timeEntry.Duration = max(timeEntry.Duration-elapsed, 0)
 			var projectAlias string
 			for _, project := range projectConfigs {
 				if project.Id == timeEntry.ProjectId {
@@ -371,7 +625,7 @@ func StopCommand() {
 
 						if err != nil {
 							fmt.Println(err.Error())
-							return
+							return nil
 						}
 						UpsertWorklogEntry(WorklogEntry{timeEntry.Id, timeEntry.Duration})
 					}
@@ -379,177 +633,114 @@ func StopCommand() {
 			}
 
 			fmt.Printf("Stopped %s%s%s\n", chalk.Red, projectAlias, chalk.Reset)
-			err := UpdateTimeEntry(timeEntry)
+			err := client.UpdateTimeEntry(timeEntry)
 			if err != nil {
-				panic(err)
+				return err
 			}
+			recordStoppedTimeEntry(timeEntry.Id)
 		}
 	}
+
+	return nil
 }
 
 func TodayCommand() {
-	err := EnsureLoggedIn()
-	if err != nil {
-		fmt.Println(chalk.Yellow.Color("Please login first using the 'login' command"))
-		return
-	}
-
 	var args struct {
-		Color bool `cli:"-c, --color, enable colors in the output"`
+		Color    bool          `cli:"-c, --color, enable colors in the output"`
+		Watch    bool          `cli:"-w, --watch, Show a full-screen dashboard that updates live and lets you start/stop projects"`
+		Interval time.Duration `cli:"--interval, How often --watch fetches the time entries to pick up changes made elsewhere" default:"30s"`
 	}
-	_, err = mcli.Parse(&args)
+	_, err := mcli.Parse(&args)
 	if err != nil {
 		panic(err)
 	}
 
-	timeEntries, err := GetTodaysTimeEntries()
-
-	if err != nil {
-		panic(err)
+	client := loggedInClient()
+	if client == nil {
+		return
 	}
 
-	cfg, _ := ReadConfig()
-	projectConfigs := cfg.Projects
-
-	var projects []Project
-
-	if len(timeEntries) == 0 {
-		fmt.Println("No time entries for today")
+	if args.Watch {
+		err := watchToday(client, args.Color, max(args.Interval, time.Second))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, chalk.Red.Color(err.Error()))
+			os.Exit(ExitError)
+		}
 		return
 	}
 
-	var overallTime int
-	var longestComment int
-
-	for _, timeEntry := range timeEntries {
-		overallTime += timeEntry.Duration
-		longestComment = max(longestComment, len(timeEntry.Comment))
+	timeEntries, err := client.GetTodaysTimeEntries()
+	if err == nil {
+		saveState(timeEntries, NewProjectNames(client), time.Now())
 	}
 
-	// todo: use more colors with chalk
-	for _, timeEntry := range timeEntries {
-		hours := timeEntry.Duration / 3600
-		minutes := (timeEntry.Duration % 3600) / 60
-		timeString := fmt.Sprintf("%02dh %02dm", hours, minutes)
-
-		var projectAlias string
-		for _, project := range projectConfigs {
-			if project.Id == timeEntry.ProjectId {
-				projectAlias = project.Alias
-				break
-			}
+	if format := outputFormat(); format != OutputText {
+		if err == nil {
+			err = writeTimeEntries(os.Stdout, format, timeEntries, NewProjectNames(client))
 		}
-
-		// if we don't have an alias for this project, use the full name
-		if projectAlias == "" {
-			if projects == nil {
-				projects, err = GetProjects()
-				if err != nil {
-					panic(err)
-				}
-			}
-			for _, project := range projects {
-				if project.Id == timeEntry.ProjectId {
-					projectAlias = project.Name
-					break
-				}
-			}
+		if err != nil {
+			exitOnApiError(err)
 		}
+		return
+	}
 
-		comment := strings.ReplaceAll(timeEntry.Comment, "\n", " ")
-		if timeEntry.Running {
-			fmt.Printf("%-10s | ⌛ %s | 📝 %-*s\n", projectAlias, timeString, longestComment, comment)
-		} else {
-			if args.Color {
-				fmt.Printf("%-19s %s    %s %s 📝 %-*s\n", chalk.Dim.TextStyle(projectAlias), chalk.Dim.TextStyle("|"), chalk.Dim.TextStyle(timeString), chalk.Dim.TextStyle("|"), longestComment, chalk.Dim.TextStyle(comment))
-			} else {
-				fmt.Printf("%-10s |    %s | 📝 %-*s\n", projectAlias, timeString, longestComment, comment)
-			}
-		}
+	if aerion.IsOffline(err) {
+		fmt.Println(chalk.Yellow.Color("Aerion is unreachable, can't list today's time entries"))
+		printPendingOperations()
+		return
+	}
+	if err != nil {
+		exitOnApiError(err)
 	}
 
-	hours := overallTime / 3600
-	minutes := (overallTime % 3600) / 60
-	time := fmt.Sprintf("%02dh %02dm", hours, minutes)
-	if args.Color {
-		fmt.Printf("%-19s %s    %s\n", chalk.Dim.TextStyle("total"), chalk.Dim.TextStyle("|"), chalk.Dim.TextStyle(time))
-	} else {
-		fmt.Printf("%-10s |    %s\n", "total", time)
+	if len(timeEntries) == 0 {
+		fmt.Println("No time entries for today")
+		printPendingOperations()
+		return
 	}
-}
 
-func YesterdayCommand() {
-	err := EnsureLoggedIn()
+	err = printTimeEntries(os.Stdout, timeEntries, NewProjectNames(client), args.Color, true)
 	if err != nil {
-		fmt.Println(chalk.Yellow.Color("Please login first using the 'login' command"))
-		return
+		exitOnApiError(err)
 	}
 
-	timeEntries, err := GetYesterdaysTimeEntries()
+	printPendingOperations()
+}
 
+func YesterdayCommand() {
+	_, err := mcli.Parse(nil)
 	if err != nil {
 		panic(err)
 	}
 
-	cfg, _ := ReadConfig()
-	projectConfigs := cfg.Projects
-
-	var projects []Project
-
-	if len(timeEntries) == 0 {
-		fmt.Println("No time entries for yesterday")
+	client := loggedInClient()
+	if client == nil {
 		return
 	}
 
-	var overallTime int
-	var longestComment int
+	timeEntries, err := getYesterdaysTimeEntries(client)
 
-	for _, timeEntry := range timeEntries {
-		overallTime += timeEntry.Duration
-		longestComment = max(longestComment, len(timeEntry.Comment))
+	if err != nil {
+		exitOnApiError(err)
 	}
 
-	for _, timeEntry := range timeEntries {
-		hours := timeEntry.Duration / 3600
-		minutes := (timeEntry.Duration % 3600) / 60
-		timeString := fmt.Sprintf("%02dh %02dm", hours, minutes)
-
-		var projectAlias string
-		for _, project := range projectConfigs {
-			if project.Id == timeEntry.ProjectId {
-				projectAlias = project.Alias
-				break
-			}
-		}
-
-		// if we don't have an alias for this project, use the full name
-		if projectAlias == "" {
-			if projects == nil {
-				projects, err = GetProjects()
-				if err != nil {
-					panic(err)
-				}
-			}
-			for _, project := range projects {
-				if project.Id == timeEntry.ProjectId {
-					projectAlias = project.Name
-					break
-				}
-			}
+	if format := outputFormat(); format != OutputText {
+		err = writeTimeEntries(os.Stdout, format, timeEntries, NewProjectNames(client))
+		if err != nil {
+			exitOnApiError(err)
 		}
+		return
+	}
 
-		comment := strings.ReplaceAll(timeEntry.Comment, "\n", " ")
-		if timeEntry.Running {
-			fmt.Printf("%-10s | ⌛ %s | 📝 %-*s\n", projectAlias, timeString, longestComment, comment)
-		} else {
-			fmt.Printf("%-10s |    %s | 📝 %-*s\n", projectAlias, timeString, longestComment, comment)
-		}
+	if len(timeEntries) == 0 {
+		fmt.Println("No time entries for yesterday")
+		return
 	}
 
-	hours := overallTime / 3600
-	minutes := (overallTime % 3600) / 60
-	time := fmt.Sprintf("%02dh %02dm", hours, minutes)
-	fmt.Printf("%-10s |    %s\n", "total", time)
+	err = printTimeEntries(os.Stdout, timeEntries, NewProjectNames(client), false, true)
+	if err != nil {
+		exitOnApiError(err)
+	}
 }
 
 func SecondsToHoursMinutes(seconds int) string {
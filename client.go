@@ -1,462 +1,106 @@
 package main
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
-	"strconv"
+	"os"
 	"strings"
 	"time"
-)
-
-type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int    `json:"expires_in"`
-	Error        string `json:"error"`
-	Raw          string `json:"raw"`
-	Status       int    `json:"status"`
-}
-
-var publicApiToken = "Basic " + base64.StdEncoding.EncodeToString([]byte("coffeecup-cli:public"))
-
-func GetApiBaseUrl() (string, error) {
-	cfg, err := ReadConfig()
-	if err != nil {
-		return "", err
-	}
-
-	if cfg.User.Company == "" {
-		return "", fmt.Errorf("No company set. Are you logged in? Please run the login command first.")
-	}
-	return "https://" + cfg.User.Company + ".aerion.app", nil
-}
-
-func EnsureLoggedIn() error {
-	cfg, err := ReadConfig()
-	if err != nil {
-		return err
-	}
-
-	if cfg.User.ExpiresAt > time.Now().Unix() {
-		return nil
-	}
-
-	return LoginWithRefreshToken()
-}
-
-// returns accesstoken, refreshtoken, and error
-func LoginWithPassword(username string, password string) error {
-	reqBody := url.Values{
-		"grant_type": []string{"password"},
-		"username":   []string{username},
-		"password":   []string{password},
-	}
-
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", apiBaseURL+"/oauth2/token", strings.NewReader(reqBody.Encode()))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Authorization", publicApiToken)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("companyurl", apiBaseURL)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	var responseBody TokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&responseBody)
-	if err != nil {
-		panic(err)
-	}
-
-	if responseBody.Error != "" {
-		return fmt.Errorf(responseBody.Raw)
-	}
-
-	StoreTokens(responseBody.AccessToken, responseBody.RefreshToken, responseBody.ExpiresIn)
-
-	return nil
-}
-
-type User struct {
-	Id    int    `json:"id"`
-	Email string `json:"email"`
-}
-
-type UserResponse struct {
-	User User
-}
-
-func GetUser() (User, error) {
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return User{}, err
-	}
-
-	req, err := http.NewRequest("GET", apiBaseURL+"/v1/users/me", nil)
-	if err != nil {
-		return User{}, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GetAccessTokenFromConfig())
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return User{}, err
-	}
-	if resp.StatusCode != 200 {
-		return User{}, fmt.Errorf("unauthorized")
-	}
-	defer resp.Body.Close()
-
-	var responseBody UserResponse
-	err = json.NewDecoder(resp.Body).Decode(&responseBody)
-	if err != nil {
-		panic(err)
-	}
-
-	return responseBody.User, nil
-}
-
-func LoginWithRefreshToken() error {
-	refreshToken := GetRefreshTokenFromConfig()
-	if refreshToken == "" {
-		return fmt.Errorf("no refresh token found")
-	}
-	reqBody := url.Values{
-		"grant_type":    []string{"refresh_token"},
-		"refresh_token": []string{refreshToken},
-	}
 
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", apiBaseURL+"/oauth2/token", strings.NewReader(reqBody.Encode()))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Authorization", publicApiToken)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	var responseBody TokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&responseBody)
-	if err != nil {
-		panic(err)
-	}
-	if responseBody.Error != "" {
-		return fmt.Errorf(responseBody.Raw)
-	}
-
-	StoreTokens(responseBody.AccessToken, responseBody.RefreshToken, responseBody.ExpiresIn)
-	return nil
-}
-
-type Project struct {
-	Id   int
-	Name string
-}
+	"github.com/fischeversenker/aerion-cli/aerion"
+)
 
-type ProjectsResponse struct {
-	Projects []Project `json:"projects"`
-	Meta     struct {
-		Total int `json:"total"`
-	} `json:"Meta"`
-	Status int    `json:"status"`
-	Error  string `json:"error"`
-	Raw    string `json:"raw"`
+// AerionClient is the API client of a command.
+type AerionClient struct {
+	*aerion.Client
+	// Action collects the changes of the running command for the undo
+	// command. Nil if the command isn't recorded.
+	Action *Action
 }
 
-func GetProjects() ([]Project, error) {
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequest("GET", apiBaseURL+"/v1/projects?status=1", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GetAccessTokenFromConfig())
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// ConfigTokenSource is the TokenSource backed by the user's config file.
+type ConfigTokenSource struct{}
 
-	var projectsResponse ProjectsResponse
-	err = json.NewDecoder(resp.Body).Decode(&projectsResponse)
-	if err != nil {
-		return nil, err
-	}
-	if projectsResponse.Status == 401 {
-		return nil, fmt.Errorf("unauthorized")
-	}
-	if projectsResponse.Error != "" {
-		return nil, fmt.Errorf(projectsResponse.Raw)
-	}
-
-	projects := make([]Project, int(projectsResponse.Meta.Total))
-	for i, p := range projectsResponse.Projects {
-		projects[i] = Project{p.Id, p.Name}
-	}
-	return projects, nil
+func (ConfigTokenSource) AccessToken() string {
+	return GetAccessTokenFromConfig()
 }
 
-type TimeEntry struct {
-	Id           int    `json:"id"`
-	ProjectId    int    `json:"project"`
-	TaskId       int    `json:"task"`
-	TeamId       int    `json:"team"`
-	UserId       int    `json:"user"`
-	Comment      string `json:"comment"`
-	Running      bool   `json:"running"`
-	CreatedAt    string `json:"createdAt"`
-	Day          string `json:"day"`
-	Duration     int    `json:"duration"`
-	Sorting      int    `json:"sorting"`
-	TrackingType string `json:"trackingType"`
+func (ConfigTokenSource) RefreshToken() string {
+	return GetRefreshTokenFromConfig()
 }
 
-type TimeEntriesResponse struct {
-	TimeEntries []TimeEntry `json:"timeEntries"`
-	Meta        struct {
-		Total int `json:"total"`
-	} `json:"Meta"`
-	Status int    `json:"status"`
-	Error  string `json:"error"`
-	Raw    string `json:"raw"`
+func (ConfigTokenSource) ExpiresAt() int64 {
+	cfg, _ := ReadConfig()
+	return cfg.User.ExpiresAt
 }
 
-func GetTodaysTimeEntries() ([]TimeEntry, error) {
-	today := time.Now().Format("2006-01-02")
-	return GetTimeEntriesForDay(today)
+func (ConfigTokenSource) StoreTokens(accessToken string, refreshToken string, expiresIn int) error {
+	return StoreTokens(accessToken, refreshToken, expiresIn)
 }
 
-// this actually returns the time entries of the previous working day.
-// it doesn't need to be yesterday, could be last Friday if it's a Monday today.
-func GetYesterdaysTimeEntries() ([]TimeEntry, error) {
-	today := time.Now()
-	yesterday := today.AddDate(0, 0, -1)
-	yesterdaysWeekday := yesterday.Weekday()
-	for ; yesterdaysWeekday == time.Saturday || yesterdaysWeekday == time.Sunday; yesterdaysWeekday = yesterday.Weekday() {
-		yesterday = yesterday.AddDate(0, 0, -1)
-	}
-	yesterdayFormatted := yesterday.Format("2006-01-02")
-	return GetTimeEntriesForDay(yesterdayFormatted)
-}
-
-func GetTimeEntriesForDay(day string) ([]TimeEntry, error) {
-	userId := strconv.Itoa(GetUserIdFromConfig())
+// NewAerionClientFromConfig creates a client for the company, tokens, user and
+// network settings stored in the config file. The fields set in network
+// override the network settings of the config.
+func NewAerionClientFromConfig(network aerion.NetworkConfig) (*AerionClient, error) {
 	apiBaseURL, err := GetApiBaseUrl()
 	if err != nil {
 		return nil, err
 	}
 
-	url := apiBaseURL + "/v1/timeentries?limit=1000&user=" + userId + "&day=" + day + "&sort=day%20ASC,sorting%20ASC"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GetAccessTokenFromConfig())
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var timeEntriesResponse TimeEntriesResponse
-	err = json.NewDecoder(resp.Body).Decode(&timeEntriesResponse)
+	cfg, _ := ReadConfig()
+	httpClient, err := aerion.NewHttpClient(cfg.Network.Merge(network))
 	if err != nil {
 		return nil, err
 	}
-	if timeEntriesResponse.Status == 401 {
-		return nil, fmt.Errorf("unauthorized")
-	}
-	if timeEntriesResponse.Error != "" {
-		return nil, fmt.Errorf(timeEntriesResponse.Raw)
-	}
 
-	return timeEntriesResponse.TimeEntries, nil
+	client := aerion.NewClient(apiBaseURL, httpClient, ConfigTokenSource{})
+	client.UserId = cfg.User.Id
+	return &AerionClient{Client: client}, nil
 }
 
-func GetLastTimeEntryForProject(projectId int) (TimeEntry, error) {
-	userId := strconv.Itoa(GetUserIdFromConfig())
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return TimeEntry{}, err
-	}
+// ApiUrlEnvVar overrides the API endpoint from the config file.
+const ApiUrlEnvVar = "AERION_API_URL"
 
-	url := apiBaseURL + "/v1/timeentries?limit=1&user=" + userId + "&project=" + strconv.Itoa(projectId) + "&sort=day%20DESC"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return TimeEntry{}, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GetAccessTokenFromConfig())
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return TimeEntry{}, err
+// GetApiBaseUrl returns the API endpoint. The AERION_API_URL environment
+// variable takes precedence over the configured base URL, which in turn takes
+// precedence over the one derived from the company prefix.
+func GetApiBaseUrl() (string, error) {
+	if baseUrl := os.Getenv(ApiUrlEnvVar); baseUrl != "" {
+		return strings.TrimSuffix(baseUrl, "/"), nil
 	}
-	defer resp.Body.Close()
 
-	var timeEntriesResponse TimeEntriesResponse
-	err = json.NewDecoder(resp.Body).Decode(&timeEntriesResponse)
+	cfg, err := ReadConfig()
 	if err != nil {
-		return TimeEntry{}, err
+		return "", err
 	}
 
-	if timeEntriesResponse.Status == 401 {
-		return TimeEntry{}, fmt.Errorf("unauthorized")
+	if cfg.User.BaseUrl != "" {
+		return strings.TrimSuffix(cfg.User.BaseUrl, "/"), nil
 	}
-	if timeEntriesResponse.Error != "" {
-		return TimeEntry{}, fmt.Errorf(timeEntriesResponse.Raw)
-	}
-	if timeEntriesResponse.Meta.Total == 0 {
-		return TimeEntry{}, fmt.Errorf("no time entries found")
+	if cfg.User.Company == "" {
+		return "", fmt.Errorf("No company set. Are you logged in? Please run the login command first.")
 	}
-
-	return timeEntriesResponse.TimeEntries[0], nil
+	return "https://" + cfg.User.Company + ".aerion.app", nil
 }
 
-func UpdateTimeEntry(timeEntry TimeEntry) error {
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return err
-	}
-
-	url := apiBaseURL + "/v1/timeEntries/" + strconv.Itoa(timeEntry.Id)
-
-	type TimeEntryUpdate struct {
-		TimeEntry TimeEntry `json:"timeEntry"`
-	}
-
-	timeEntryToBeUpdated := TimeEntryUpdate{
-		TimeEntry: timeEntry,
-	}
-	payload, err := json.Marshal(timeEntryToBeUpdated)
-	if err != nil {
-		return err
+// ParseCompanyInput tells apart a company prefix ("acme") from a full API
+// endpoint ("https://time.acme.com" or "time.acme.com") as entered on login.
+// It returns either the prefix or the normalized base URL.
+func ParseCompanyInput(input string) (company string, baseUrl string) {
+	input = strings.TrimSpace(input)
+	if strings.Contains(input, "://") {
+		return "", strings.TrimSuffix(input, "/")
 	}
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(payload))
-	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-	if err != nil {
-		return err
+	if strings.ContainsAny(input, ".:/") {
+		return "", "https://" + strings.TrimSuffix(input, "/")
 	}
-
-	req.Header.Set("Authorization", "Bearer "+GetAccessTokenFromConfig())
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	var timeEntryUpdateResponse struct {
-		Status int    `json:"status"`
-		Raw    string `json:"raw"`
-		Error  string `json:"error"`
-	}
-	err = json.NewDecoder(resp.Body).Decode(&timeEntryUpdateResponse)
-	if err != nil {
-		return err
-	}
-	if timeEntryUpdateResponse.Status == 401 {
-		return fmt.Errorf("unauthorized")
-	}
-	if timeEntryUpdateResponse.Error != "" {
-		return fmt.Errorf(timeEntryUpdateResponse.Raw)
-	}
-
-	return nil
-}
-
-type NewTimeEntry struct {
-	ProjectId    int    `json:"project"`
-	Comment      string `json:"comment"`
-	Day          string `json:"day"`
-	Running      bool   `json:"running"`
-	Duration     int    `json:"duration"`
-	Sorting      int    `json:"sorting"`
-	TaskId       int    `json:"task"`
-	TrackingType string `json:"trackingType"`
-	UserId       int    `json:"user"`
+	return input, ""
 }
 
-func CreateTimeEntry(timeEntry NewTimeEntry) error {
-	apiBaseURL, err := GetApiBaseUrl()
-	if err != nil {
-		return err
-	}
-
-	url := apiBaseURL + "/v1/timeEntries"
-
-	type TimeEntryCreation struct {
-		TimeEntry NewTimeEntry `json:"timeEntry"`
-	}
-
-	timeEntryToBeCreated := TimeEntryCreation{
-		TimeEntry: timeEntry,
-	}
-	payload, err := json.Marshal(timeEntryToBeCreated)
-	if err != nil {
-		return err
-	}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
-	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GetAccessTokenFromConfig())
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	var timeEntriesCreationResponse struct {
-		Status int    `json:"status"`
-		Raw    string `json:"raw"`
-		Error  string `json:"error"`
-	}
-	err = json.NewDecoder(resp.Body).Decode(&timeEntriesCreationResponse)
-	if err != nil {
-		return err
-	}
-	if timeEntriesCreationResponse.Status == 401 {
-		return fmt.Errorf("unauthorized")
-	}
-	if timeEntriesCreationResponse.Error != "" {
-		return fmt.Errorf(timeEntriesCreationResponse.Raw)
-	}
-
-	return nil
+// getYesterdaysTimeEntries actually returns the time entries of the previous
+// working day. It doesn't need to be yesterday, could be last Friday if it's a
+// Monday today. The working days are taken from the schedule in the config.
+func getYesterdaysTimeEntries(client *AerionClient) ([]aerion.TimeEntry, error) {
+	cfg, _ := ReadConfig()
+	yesterday := cfg.Schedule.PreviousWorkingDay(time.Now())
+	return client.GetTimeEntriesForDay(yesterday.Format("2006-01-02"))
 }
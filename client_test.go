@@ -1,12 +1,20 @@
 package main
 
 import (
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
 )
 
 func TestGetApiBaseUrlReturnsCompany(t *testing.T) {
 	tempDir := t.TempDir()
+	t.Setenv(ApiUrlEnvVar, "")
 
 	oldHome := os.Getenv("HOME")
 	os.Setenv("HOME", tempDir)
@@ -33,6 +41,7 @@ func TestGetApiBaseUrlReturnsCompany(t *testing.T) {
 
 func TestGetApiBaseUrlMissingCompany(t *testing.T) {
 	tempDir := t.TempDir()
+	t.Setenv(ApiUrlEnvVar, "")
 
 	oldHome := os.Getenv("HOME")
 	os.Setenv("HOME", tempDir)
@@ -48,3 +57,128 @@ func TestGetApiBaseUrlMissingCompany(t *testing.T) {
 		t.Fatal("expected error when company is not set")
 	}
 }
+
+func TestGetApiBaseUrlPrefersConfiguredBaseUrl(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", oldHome)
+	})
+	t.Setenv(ApiUrlEnvVar, "")
+
+	cfg := Config{}
+	cfg.User.Company = "acme"
+	cfg.User.BaseUrl = "https://time.acme.com/"
+
+	if err := WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig error: %v", err)
+	}
+
+	baseURL, err := GetApiBaseUrl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if baseURL != "https://time.acme.com" {
+		t.Fatalf("expected configured base url, got %q", baseURL)
+	}
+
+	t.Setenv(ApiUrlEnvVar, "http://localhost:8765")
+	baseURL, err = GetApiBaseUrl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if baseURL != "http://localhost:8765" {
+		t.Fatalf("expected base url from %s, got %q", ApiUrlEnvVar, baseURL)
+	}
+}
+
+func TestParseCompanyInput(t *testing.T) {
+	tests := []struct {
+		input   string
+		company string
+		baseUrl string
+	}{
+		{"acme\n", "acme", ""},
+		{"https://time.acme.com/", "", "https://time.acme.com"},
+		{"time.acme.com", "", "https://time.acme.com"},
+		{"http://localhost:8765", "", "http://localhost:8765"},
+	}
+
+	for _, test := range tests {
+		company, baseUrl := ParseCompanyInput(test.input)
+		if company != test.company || baseUrl != test.baseUrl {
+			t.Errorf("ParseCompanyInput(%q) = %q, %q; expected %q, %q", test.input, company, baseUrl, test.company, test.baseUrl)
+		}
+	}
+}
+
+func TestDescribeApiErrorExitCodes(t *testing.T) {
+	tests := []struct {
+		err      error
+		exitCode int
+	}{
+		{aerion.ErrLoginRequired, ExitLoginRequired},
+		{&aerion.APIError{StatusCode: http.StatusNotFound}, ExitNotFound},
+		{&aerion.APIError{StatusCode: http.StatusBadRequest}, ExitInvalidInput},
+		{&aerion.APIError{StatusCode: http.StatusInternalServerError}, ExitServerError},
+		{errors.New("boom"), ExitError},
+	}
+
+	for _, test := range tests {
+		if _, exitCode := describeApiError(test.err); exitCode != test.exitCode {
+			t.Errorf("expected exit code %d for %v, got %d", test.exitCode, test.err, exitCode)
+		}
+	}
+}
+
+func TestIsLoginRequired(t *testing.T) {
+	tests := []struct {
+		err           error
+		loginRequired bool
+	}{
+		{aerion.ErrLoginRequired, true},
+		{&aerion.APIError{StatusCode: http.StatusUnauthorized}, true},
+		{&aerion.APIError{StatusCode: http.StatusBadRequest, Code: "invalid_grant"}, true},
+		{&aerion.APIError{StatusCode: http.StatusServiceUnavailable}, false},
+		{&aerion.APIError{StatusCode: http.StatusInternalServerError, Endpoint: "POST /oauth2/token"}, false},
+		{errors.New("boom"), false},
+	}
+
+	for _, test := range tests {
+		if loginRequired := isLoginRequired(test.err); loginRequired != test.loginRequired {
+			t.Errorf("isLoginRequired(%v) = %t, expected %t", test.err, loginRequired, test.loginRequired)
+		}
+	}
+}
+
+func TestNewAerionClientFromConfigPrefersNetworkOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv(ApiUrlEnvVar, "")
+
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	caBundle := filepath.Join(tempDir, "ca.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{}
+	cfg.User.Company = "acme"
+	cfg.Network.CaBundle = filepath.Join(tempDir, "missing.pem")
+	if err := WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewAerionClientFromConfig(aerion.NetworkConfig{})
+	if !errors.Is(err, aerion.ErrInvalidNetworkConfig) {
+		t.Fatalf("expected the missing CA bundle to be reported, got %v", err)
+	}
+	_, err = NewAerionClientFromConfig(aerion.NetworkConfig{CaBundle: caBundle})
+	if err != nil {
+		t.Fatalf("expected the CA bundle flag to replace the broken one, got %v", err)
+	}
+}
@@ -5,14 +5,15 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/fischeversenker/aerion-cli/aerion"
 	toml "github.com/pelletier/go-toml/v2"
 )
 
 type ProjectConfig struct {
-	Alias         string
-	Name          string
-	Id            int
-	DefaultTaskId int
+	Alias         string `json:"alias"`
+	Name          string `json:"name"`
+	Id            int    `json:"id"`
+	DefaultTaskId int    `json:"defaultTaskId"`
 }
 
 type JiraConfig struct {
@@ -27,9 +28,14 @@ type Config struct {
 		RefreshToken string
 		Id           int
 		Company      string
+		// BaseUrl overrides the API endpoint derived from Company, e.g. for
+		// custom domains or test instances.
+		BaseUrl string
 	}
 	Projects map[string]ProjectConfig
 	Jira     JiraConfig
+	Network  aerion.NetworkConfig
+	Schedule ScheduleConfig
 }
 
 const (
@@ -42,7 +48,8 @@ func StoreTokens(accessToken string, refreshToken string, expiresIn int) error {
 	cfg.User.AccessToken = accessToken
 	cfg.User.RefreshToken = refreshToken
 
-	expiresAt := time.Now().Unix() + int64(expiresIn)*1000
+	// expiresIn is given in seconds
+	expiresAt := time.Now().Unix() + int64(expiresIn)
 	cfg.User.ExpiresAt = expiresAt
 
 	return WriteConfig(cfg)
@@ -73,6 +80,16 @@ func GetUserIdFromConfig() int {
 func StoreCompany(company string) {
 	cfg, _ := ReadConfig()
 	cfg.User.Company = company
+	cfg.User.BaseUrl = ""
+
+	WriteConfig(cfg)
+}
+
+// StoreBaseUrl stores a full API endpoint. It replaces any company prefix.
+func StoreBaseUrl(baseUrl string) {
+	cfg, _ := ReadConfig()
+	cfg.User.BaseUrl = baseUrl
+	cfg.User.Company = ""
 
 	WriteConfig(cfg)
 }
@@ -82,6 +99,17 @@ func GetCompanyFromConfig() string {
 	return cfg.User.Company
 }
 
+// findProjectByAlias looks up the configured project with the given alias.
+func findProjectByAlias(alias string) (ProjectConfig, bool) {
+	cfg, _ := ReadConfig()
+	for _, project := range cfg.Projects {
+		if project.Alias == alias {
+			return project, true
+		}
+	}
+	return ProjectConfig{}, false
+}
+
 func ReadConfig() (Config, error) {
 	err := os.MkdirAll(filepath.Join(os.Getenv("HOME"), ConfigFolderPath), os.ModePerm)
 	if err != nil {
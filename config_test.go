@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestReadConfig(t *testing.T) {
@@ -91,3 +92,21 @@ func TestReadNotExistingConfigError(t *testing.T) {
 		t.Error("Expected an error, got nil")
 	}
 }
+
+func TestStoreTokensExpiresInSeconds(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", oldHome)
+
+	before := time.Now().Unix()
+	if err := StoreTokens("access", "refresh", 3600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ := ReadConfig()
+	if cfg.User.ExpiresAt < before+3600 || cfg.User.ExpiresAt > time.Now().Unix()+3600 {
+		t.Errorf("Expected token to expire in one hour, got %d (now %d)", cfg.User.ExpiresAt, before)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DayFormat = "2006-01-02"
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// ParseDateRange parses a date expression relative to now into its first and
+// last day, both inclusive. Supported are dates like "2026-10-01", "today",
+// "yesterday", weekdays ("friday" is the last friday up to today, "last
+// friday" the one before today), "3 days ago", "this week", "last week",
+// "this month", "last month", and ranges of those like
+// "2026-10-01..2026-10-07" or "last monday..today".
+func ParseDateRange(expr string, now time.Time) (from time.Time, to time.Time, err error) {
+	if start, end, found := strings.Cut(expr, ".."); found {
+		from, _, err = ParseDateRange(start, now)
+		if err != nil {
+			return
+		}
+		_, to, err = ParseDateRange(end, now)
+		if err != nil {
+			return
+		}
+		if to.Before(from) {
+			err = fmt.Errorf("the range '%s' ends before it starts", expr)
+		}
+		return
+	}
+
+	today := truncateToDay(now)
+	expr = strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+
+	switch expr {
+	case "", "today":
+		return today, today, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return yesterday, yesterday, nil
+	case "this week":
+		monday := startOfWeek(today)
+		return monday, monday.AddDate(0, 0, 6), nil
+	case "last week":
+		monday := startOfWeek(today).AddDate(0, 0, -7)
+		return monday, monday.AddDate(0, 0, 6), nil
+	case "this month":
+		first := today.AddDate(0, 0, 1-today.Day())
+		return first, first.AddDate(0, 1, -1), nil
+	case "last month":
+		first := today.AddDate(0, 0, 1-today.Day()).AddDate(0, -1, 0)
+		return first, first.AddDate(0, 1, -1), nil
+	}
+
+	if day, err := time.ParseInLocation(DayFormat, expr, now.Location()); err == nil {
+		return day, day, nil
+	}
+
+	if weekday, ok := weekdays[expr]; ok {
+		day := previousWeekday(today, weekday, true)
+		return day, day, nil
+	}
+	if name, found := strings.CutPrefix(expr, "last "); found {
+		if weekday, ok := weekdays[name]; ok {
+			day := previousWeekday(today, weekday, false)
+			return day, day, nil
+		}
+	}
+
+	if count, found := strings.CutSuffix(expr, " days ago"); found {
+		if days, err := strconv.Atoi(count); err == nil && days >= 0 {
+			day := today.AddDate(0, 0, -days)
+			return day, day, nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("couldn't understand the date '%s'", expr)
+}
+
+// ParseDate parses a date expression that must refer to a single day.
+func ParseDate(expr string, now time.Time) (time.Time, error) {
+	from, to, err := ParseDateRange(expr, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !from.Equal(to) {
+		return time.Time{}, fmt.Errorf("'%s' is more than a single day", expr)
+	}
+	return from, nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the monday of the day's week.
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// previousWeekday returns the latest day with the given weekday before the
+// given day, or on it if inclusive is set.
+func previousWeekday(day time.Time, weekday time.Weekday, inclusive bool) time.Time {
+	if !inclusive {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day.AddDate(0, 0, -(int(day.Weekday())-int(weekday)+7)%7)
+}
+
+// ParseDurationInput parses a duration like "1h30m", "45m", "1.5h" or "1:30"
+// into seconds.
+func ParseDurationInput(input string) (int, error) {
+	input = strings.ToLower(strings.ReplaceAll(input, " ", ""))
+	if hours, minutes, found := strings.Cut(input, ":"); found {
+		h, errHours := strconv.Atoi(hours)
+		m, errMinutes := strconv.Atoi(minutes)
+		if errHours != nil || errMinutes != nil || h < 0 || m < 0 || m > 59 {
+			return 0, fmt.Errorf("couldn't understand the duration '%s'", input)
+		}
+		return h*3600 + m*60, nil
+	}
+
+	duration, err := time.ParseDuration(input)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("couldn't understand the duration '%s', use e.g. 1h30m, 45m or 1:30", input)
+	}
+	return int(duration.Seconds()), nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	// a Saturday
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		expr string
+		from string
+		to   string
+	}{
+		{"", "2026-10-17", "2026-10-17"},
+		{"today", "2026-10-17", "2026-10-17"},
+		{"yesterday", "2026-10-16", "2026-10-16"},
+		{"2026-10-01", "2026-10-01", "2026-10-01"},
+		{"saturday", "2026-10-17", "2026-10-17"},
+		{"last saturday", "2026-10-10", "2026-10-10"},
+		{"Last  Monday", "2026-10-12", "2026-10-12"},
+		{"fri", "2026-10-16", "2026-10-16"},
+		{"3 days ago", "2026-10-14", "2026-10-14"},
+		{"this week", "2026-10-12", "2026-10-18"},
+		{"last week", "2026-10-05", "2026-10-11"},
+		{"this month", "2026-10-01", "2026-10-31"},
+		{"last month", "2026-09-01", "2026-09-30"},
+		{"2026-10-01..2026-10-07", "2026-10-01", "2026-10-07"},
+		{"last week..yesterday", "2026-10-05", "2026-10-16"},
+	}
+
+	for _, test := range tests {
+		from, to, err := ParseDateRange(test.expr, now)
+		if err != nil {
+			t.Errorf("ParseDateRange(%q) error: %v", test.expr, err)
+			continue
+		}
+		if from.Format(DayFormat) != test.from || to.Format(DayFormat) != test.to {
+			t.Errorf("ParseDateRange(%q) = %s..%s, expected %s..%s", test.expr, from.Format(DayFormat), to.Format(DayFormat), test.from, test.to)
+		}
+	}
+}
+
+func TestParseDateRangeRejectsInvalidExpressions(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.Local)
+
+	for _, expr := range []string{"someday", "2026-13-01", "last year", "2026-10-07..2026-10-01"} {
+		if _, _, err := ParseDateRange(expr, now); err == nil {
+			t.Errorf("expected an error for %q", expr)
+		}
+	}
+}
+
+func TestParseDurationInput(t *testing.T) {
+	tests := map[string]int{
+		"1h30m": 5400,
+		"45m":   2700,
+		"1.5h":  5400,
+		"1:30":  5400,
+		"0:05":  300,
+		"2H":    7200,
+	}
+	for input, expected := range tests {
+		seconds, err := ParseDurationInput(input)
+		if err != nil || seconds != expected {
+			t.Errorf("ParseDurationInput(%q) = %d, %v, expected %d", input, seconds, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "90", "1:75", "-1h", "soon"} {
+		if _, err := ParseDurationInput(input); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+func DeleteCommand() {
+	var args struct {
+		Position int    `cli:"position, The number of the entry in the today or show listing of the day, 1 is the first one"`
+		Id       int    `cli:"--id, The ID of the time entry, instead of its position"`
+		Day      string `cli:"-d, --day, The day of the listing the position refers to" default:"today"`
+		Empty    bool   `cli:"--empty, Delete all entries of the day that have no time booked (00h 00m) and aren't running"`
+		Yes      bool   `cli:"-y, --yes, Delete without asking for confirmation"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	var timeEntries []aerion.TimeEntry
+	if args.Empty {
+		timeEntries, err = findEmptyTimeEntries(client, args.Day)
+		if err != nil {
+			exitOnApiError(err)
+		}
+		if len(timeEntries) == 0 {
+			fmt.Println("No empty time entries to delete")
+			return
+		}
+	} else {
+		timeEntry, err := selectTimeEntry(client, args.Position, args.Id, args.Day)
+		if err != nil {
+			exitOnApiError(err)
+		}
+		timeEntries = []aerion.TimeEntry{timeEntry}
+	}
+
+	err = printTimeEntryRows(os.Stdout, timeEntries, NewProjectNames(client), false, false)
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	question := "Delete this time entry?"
+	if len(timeEntries) > 1 {
+		question = fmt.Sprintf("Delete these %d time entries?", len(timeEntries))
+	}
+	if !args.Yes && !confirm(question) {
+		fmt.Println("Nothing deleted")
+		return
+	}
+
+	err = recordAction(client, "delete", func() error {
+		for _, timeEntry := range timeEntries {
+			err := client.DeleteTimeEntry(timeEntry.Id)
+			if err != nil {
+				return err
+			}
+			client.Action.Deleted(timeEntry)
+		}
+		return nil
+	})
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	if len(timeEntries) == 1 {
+		fmt.Println(chalk.Green.Color("Deleted 1 time entry"))
+	} else {
+		fmt.Println(chalk.Green.Color(fmt.Sprintf("Deleted %d time entries", len(timeEntries))))
+	}
+}
+
+// findEmptyTimeEntries returns the stopped entries of a day that have less
+// than a minute booked, which is what's left behind by accidental starts.
+func findEmptyTimeEntries(client *AerionClient, dayExpr string) ([]aerion.TimeEntry, error) {
+	day, err := ParseDate(dayExpr, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	timeEntries, err := client.GetTimeEntriesForDay(day.Format(DayFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	var empty []aerion.TimeEntry
+	for _, timeEntry := range timeEntries {
+		if !timeEntry.Running && timeEntry.Duration < 60 {
+			empty = append(empty, timeEntry)
+		}
+	}
+	return empty, nil
+}
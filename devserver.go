@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+)
+
+func DevFakeServerCommand() {
+	var args struct {
+		Addr string `cli:"-a, --addr, The address to listen on" default:"127.0.0.1:8765"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	server := aerion.NewFakeServer()
+	for _, name := range []string{"Internal", "Customer Project", "Support"} {
+		server.AddProject(name)
+	}
+
+	fmt.Printf("Fake Aerion server listening on http://%s\n", args.Addr)
+	fmt.Printf("Point the CLI at it with %s=http://%s\n", ApiUrlEnvVar, args.Addr)
+	fmt.Printf("Login with username \"%s\" and password \"%s\"\n", server.Username, server.Password)
+	err = http.ListenAndServe(args.Addr, server)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,854 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+)
+
+type e2eEnv struct {
+	server   *aerion.FakeServer
+	projects []aerion.Project
+	// offline makes the commands talk to an unreachable address
+	offline bool
+}
+
+// setupE2E starts a fake Aerion server with two aliased projects and points
+// the commands at it using a logged in config in a temporary HOME.
+func setupE2E(t *testing.T) *e2eEnv {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", oldHome)
+	})
+
+	fake := aerion.NewFakeServer()
+	projects := []aerion.Project{fake.AddProject("Project One"), fake.AddProject("Project Two")}
+	httpServer := httptest.NewServer(fake)
+	t.Cleanup(httpServer.Close)
+
+	accessToken, refreshToken := fake.IssueTokens()
+	cfg := Config{}
+	cfg.User.Company = "fake"
+	cfg.User.Id = fake.User.Id
+	cfg.User.AccessToken = accessToken
+	cfg.User.RefreshToken = refreshToken
+	cfg.User.ExpiresAt = time.Now().Add(time.Hour).Unix()
+	cfg.Projects = map[string]ProjectConfig{}
+	for i, project := range projects {
+		cfg.Projects[strconv.Itoa(project.Id)] = ProjectConfig{
+			Alias:         "p" + strconv.Itoa(i+1),
+			Name:          project.Name,
+			Id:            project.Id,
+			DefaultTaskId: 7,
+		}
+	}
+	if err := WriteConfig(cfg); err != nil {
+		t.Fatalf("WriteConfig error: %v", err)
+	}
+
+	env := &e2eEnv{server: fake, projects: projects}
+
+	oldNewClient := newClient
+	newClient = func(aerion.NetworkConfig) (*AerionClient, error) {
+		baseUrl := httpServer.URL
+		if env.offline {
+			baseUrl = "http://127.0.0.1:1"
+		}
+		client := aerion.NewClient(baseUrl, httpServer.Client(), ConfigTokenSource{})
+		client.UserId = GetUserIdFromConfig()
+		client.Retry = aerion.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+		return &AerionClient{Client: client}, nil
+	}
+	t.Cleanup(func() {
+		newClient = oldNewClient
+	})
+
+	return env
+}
+
+// runCLI runs the CLI with the given arguments and returns what it printed.
+func runCLI(t *testing.T, args ...string) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, reader)
+		output <- buf.String()
+	}()
+
+	newApp().Run(args...)
+	writer.Close()
+	return <-output
+}
+
+// runCLIExitCode runs the CLI for a command that exits the process and returns
+// its exit code. The command runs in a copy of the test binary that repeats
+// the calling test up to here, so it must be called before the test changes
+// anything the command depends on.
+func runCLIExitCode(t *testing.T, args ...string) int {
+	t.Helper()
+
+	if os.Getenv("AERION_E2E_EXIT") == "1" {
+		runCLI(t, args...)
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$")
+	cmd.Env = append(os.Environ(), "AERION_E2E_EXIT=1")
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0
+}
+
+func TestE2EStartCreatesRunningEntry(t *testing.T) {
+	env := setupE2E(t)
+
+	out := runCLI(t, "start", "p1")
+	if !strings.Contains(out, "Started new time entry") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 {
+		t.Fatalf("expected 1 time entry, got %d", len(timeEntries))
+	}
+	timeEntry := timeEntries[0]
+	if !timeEntry.Running || timeEntry.ProjectId != env.projects[0].Id || timeEntry.TaskId != 7 {
+		t.Fatalf("unexpected time entry %+v", timeEntry)
+	}
+	if timeEntry.Day != time.Now().Format("2006-01-02") || timeEntry.Sorting != 1 {
+		t.Fatalf("unexpected day or sorting %+v", timeEntry)
+	}
+}
+
+func TestE2EStartAppendsCommentToRunningEntry(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	runCLI(t, "start", "p1", "Task A", "-amend")
+	out := runCLI(t, "start", "p1", "Task B", "-amend")
+	if !strings.Contains(out, "is running already") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 {
+		t.Fatalf("expected 1 time entry, got %d", len(timeEntries))
+	}
+	if timeEntries[0].Comment != "- Task A\n- Task B" {
+		t.Fatalf("unexpected comment %q", timeEntries[0].Comment)
+	}
+}
+
+func TestE2EStartSwitchesAndResumesProjects(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	runCLI(t, "start", "p2")
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 {
+		t.Fatalf("expected 2 time entries, got %d", len(timeEntries))
+	}
+	if timeEntries[0].Running || !timeEntries[1].Running || timeEntries[1].Sorting != 2 {
+		t.Fatalf("expected only the second entry to run: %+v", timeEntries)
+	}
+
+	out := runCLI(t, "start", "p1")
+	if !strings.Contains(out, "Resumed existing time entry") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	timeEntries = env.server.TimeEntries()
+	if len(timeEntries) != 2 {
+		t.Fatalf("expected resume instead of a new entry, got %d entries", len(timeEntries))
+	}
+	if !timeEntries[0].Running || timeEntries[1].Running {
+		t.Fatalf("expected only the first entry to run: %+v", timeEntries)
+	}
+}
+
+func TestE2EStartWithCommentCreatesNewEntry(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	runCLI(t, "start", "p1", "Task A")
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 {
+		t.Fatalf("expected 2 time entries, got %d", len(timeEntries))
+	}
+	if timeEntries[0].Running || !timeEntries[1].Running || timeEntries[1].Comment != "Task A" {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+}
+
+func TestE2EStopStopsRunningEntries(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	out := runCLI(t, "stop")
+	if !strings.Contains(out, "Stopped") || !strings.Contains(out, "p1") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	for _, timeEntry := range env.server.TimeEntries() {
+		if timeEntry.Running {
+			t.Fatalf("expected no running entries, got %+v", timeEntry)
+		}
+	}
+}
+
+func TestE2ETodayListsEntries(t *testing.T) {
+	setupE2E(t)
+
+	out := runCLI(t, "today")
+	if !strings.Contains(out, "No time entries for today") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	runCLI(t, "start", "p1", "Task A", "-amend")
+	runCLI(t, "start", "p2")
+
+	out = runCLI(t, "today")
+	for _, expected := range []string{"p1", "- Task A", "p2", "⌛", "total"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+}
+
+func TestE2ERefreshesExpiredToken(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	env.server.ExpireAccessToken()
+	runCLI(t, "stop")
+
+	for _, timeEntry := range env.server.TimeEntries() {
+		if timeEntry.Running {
+			t.Fatalf("expected stop to succeed after refreshing the token, got %+v", timeEntry)
+		}
+	}
+	if GetAccessTokenFromConfig() == "" || strings.HasPrefix(GetAccessTokenFromConfig(), "expired-") {
+		t.Fatalf("expected refreshed token to be stored, got %q", GetAccessTokenFromConfig())
+	}
+}
+
+func TestE2ERecordsOperationsWhileOffline(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	env.offline = true
+
+	out := runCLI(t, "start", "p2", "Task B", "-amend")
+	if !strings.Contains(out, "Aerion is unreachable") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	out = runCLI(t, "today")
+	if !strings.Contains(out, "Pending offline operations") || !strings.Contains(out, `start p2 "Task B"`) {
+		t.Fatalf("expected pending operations in output %q", out)
+	}
+
+	env.offline = false
+	out = runCLI(t, "today")
+	if !strings.Contains(out, "p1") || !strings.Contains(out, "Pending offline operations") {
+		t.Fatalf("expected entries and pending operations in output %q", out)
+	}
+
+	out = runCLI(t, "sync")
+	if !strings.Contains(out, "All offline operations are synced") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if journal, _ := ReadJournal(); len(journal) != 0 {
+		t.Fatalf("expected an empty journal, got %+v", journal)
+	}
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || timeEntries[0].Running || !timeEntries[1].Running || timeEntries[1].Comment != "- Task B" {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+}
+
+func TestE2ESyncUsesRecordedTimes(t *testing.T) {
+	env := setupE2E(t)
+
+	now := time.Now()
+	err := WriteJournal([]JournalEntry{
+		{Operation: JournalStart, Alias: "p1", Amend: true, At: now.Add(-2 * time.Hour)},
+		{Operation: JournalStop, At: now.Add(-30 * time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runCLI(t, "sync")
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].Running {
+		t.Fatalf("expected one stopped time entry, got %+v", timeEntries)
+	}
+	if duration := timeEntries[0].Duration; duration < 90*60-5 || duration > 90*60+5 {
+		t.Fatalf("expected a duration of 1h 30m, got %ds", duration)
+	}
+}
+
+func TestE2EOnlineCommandsSyncPendingOperationsFirst(t *testing.T) {
+	env := setupE2E(t)
+
+	env.offline = true
+	runCLI(t, "start", "p1")
+	env.offline = false
+	runCLI(t, "start", "p2")
+
+	if journal, _ := ReadJournal(); len(journal) != 0 {
+		t.Fatalf("expected an empty journal, got %+v", journal)
+	}
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || timeEntries[0].ProjectId != env.projects[0].Id || timeEntries[0].Running || !timeEntries[1].Running {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+}
+
+func TestE2EOnlineCommandsSkipRejectedOperations(t *testing.T) {
+	env := setupE2E(t)
+
+	err := WriteJournal([]JournalEntry{
+		{Operation: JournalStart, Alias: "gone", At: time.Now().Add(-time.Hour)},
+		{Operation: JournalStart, Alias: "p2", At: time.Now().Add(-30 * time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runCLI(t, "start", "p1")
+	if journal, _ := ReadJournal(); len(journal) != 0 {
+		t.Fatalf("expected an empty journal, got %+v", journal)
+	}
+	skipped, _ := ReadSkippedJournal()
+	if len(skipped) != 1 || skipped[0].Alias != "gone" || !strings.Contains(skipped[0].Error, "not found") {
+		t.Fatalf("expected the unknown alias to be skipped, got %+v", skipped)
+	}
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || timeEntries[0].ProjectId != env.projects[1].Id || timeEntries[1].ProjectId != env.projects[0].Id || !timeEntries[1].Running {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+
+	out := runCLI(t, "today")
+	if !strings.Contains(out, "Skipped offline operations") || !strings.Contains(out, "start gone") {
+		t.Fatalf("expected the skipped operation in output %q", out)
+	}
+	runCLI(t, "sync", "--drop")
+	if skipped, _ := ReadSkippedJournal(); len(skipped) != 0 {
+		t.Fatalf("expected the skipped operations to be dropped, got %+v", skipped)
+	}
+}
+
+func TestE2EShowExitsOnInvalidRange(t *testing.T) {
+	setupE2E(t)
+
+	if code := runCLIExitCode(t, "show", "next blursday"); code != ExitInvalidInput {
+		t.Fatalf("expected exit code %d, got %d", ExitInvalidInput, code)
+	}
+}
+
+func TestE2EShowListsRangeGroupedByDay(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2026-10-01", Duration: 3600, Comment: "Planning"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2026-10-02", Duration: 1800, Sorting: 1, Comment: "Review"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2026-10-02", Duration: 900, Sorting: 2})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2026-10-09", Duration: 600})
+
+	out := runCLI(t, "show", "2026-10-01..2026-10-07")
+	for _, expected := range []string{"Thursday, 2026-10-01", "Planning", "Friday, 2026-10-02", "Review", "00h 45m", "overall    |    01h 45m"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+	if strings.Contains(out, "2026-10-09") {
+		t.Errorf("unexpected entry outside of the range in %q", out)
+	}
+
+	out = runCLI(t, "show", "--from", "2026-10-09", "--to", "2026-10-09")
+	if !strings.Contains(out, "00h 10m") || strings.Contains(out, "overall") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out = runCLI(t, "show", "2026-09-01")
+	if !strings.Contains(out, "No time entries for 2026-09-01") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestE2EWeekShowsMatrix(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2026-10-05", Duration: 3600})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2026-10-06", Duration: 1800})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2026-10-06", Duration: 900})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2026-10-08", Duration: 7200})
+
+	out := runCLI(t, "week", "2026-10-07")
+	for _, expected := range []string{
+		"Week of 2026-10-05 to 2026-10-11",
+		"Mon 05",
+		"!Wed 07",
+		"p1         |  01h 00m  00h 30m        -        -        - |  01h 30m",
+		"p2         |        -  00h 15m        -  02h 00m        - |  02h 15m",
+		"total      |  01h 00m  00h 45m        -  02h 00m        - |  03h 45m",
+		"No time entries on Wednesday, 2026-10-07",
+		"No time entries on Friday, 2026-10-09",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+	if strings.Contains(out, "Sat") {
+		t.Errorf("unexpected weekend column in %q", out)
+	}
+}
+
+func TestE2EBalanceComparesBookedToTargetHours(t *testing.T) {
+	env := setupE2E(t)
+	cfg, _ := ReadConfig()
+	cfg.Schedule = ScheduleConfig{WeeklyHours: 30, WorkingDays: []string{"Mon", "Tue", "Wed"}, Start: "2024-03-04", InitialBalance: 1.5}
+	if err := WriteConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// 10 hours each from Mon 2024-03-04 to Wed 2024-03-06
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 11 * 3600})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-05", Duration: 9 * 3600})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-07", Duration: 3600})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-11", Duration: 10 * 3600})
+
+	out := runCLI(t, "balance", "2024-03-04..2024-03-10")
+	for _, expected := range []string{
+		"Mon, 2024-03-04  |  10h 00m |  11h 00m |  +01h 00m |  +02h 30m",
+		"Wed, 2024-03-06  |  10h 00m |  00h 00m |  -10h 00m |  -08h 30m",
+		"Thu, 2024-03-07  |  00h 00m |  01h 00m |  +01h 00m |  -07h 30m",
+		"overtime   |   -07h 30m",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+
+	out = runCLI(t, "balance", "--from", "2024-03-11", "--to", "2024-03-11")
+	for _, expected := range []string{"carried over     |          |          |           |  -07h 30m", "overtime   |   -07h 30m"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+}
+
+func TestE2EAddBooksFinishedEntryOnAnyDay(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-04", Duration: 3600, Sorting: 4})
+
+	out := runCLI(t, "add", "p1", "1h30m", "Retro", "--day", "2024-03-04")
+	if !strings.Contains(out, "Added 01h 30m to") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 {
+		t.Fatalf("expected 2 time entries, got %+v", timeEntries)
+	}
+	added := timeEntries[1]
+	if added.ProjectId != env.projects[0].Id || added.Day != "2024-03-04" || added.Duration != 5400 || added.Running ||
+		added.Comment != "Retro" || added.Sorting != 5 || added.TaskId != 7 {
+		t.Fatalf("unexpected time entry %+v", added)
+	}
+}
+
+func TestE2EEditChangesSelectedEntry(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 3600, Sorting: 1, Comment: "First"})
+	second := env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 1800, Sorting: 2, Comment: "Second"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-05", Duration: 600, Sorting: 3})
+
+	out := runCLI(t, "show", "2024-03-04")
+	if !strings.Contains(out, "#2  p1         |    00h 30m | 📝 Second | id "+strconv.Itoa(second.Id)) {
+		t.Errorf("expected the second entry to be numbered in output %q", out)
+	}
+
+	out = runCLI(t, "edit", "2", "--day", "2024-03-04", "--duration=+15m", "-m", "Standup", "-p", "p2", "--yes")
+	for _, expected := range []string{"duration  ", "00h 30m", "00h 45m", "Second", "Standup", "Saved"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+	edited := env.server.TimeEntries()[1]
+	if edited.Id != second.Id || edited.Duration != 2700 || edited.Comment != "Standup" || edited.ProjectId != env.projects[1].Id || edited.TaskId != 7 {
+		t.Fatalf("unexpected time entry %+v", edited)
+	}
+
+	runCLI(t, "edit", "--id", strconv.Itoa(second.Id), "--move-to", "2024-03-05", "--yes")
+	timeEntries := env.server.TimeEntries()
+	moved := timeEntries[len(timeEntries)-1]
+	if moved.Id != second.Id || moved.Day != "2024-03-05" || moved.Sorting != 4 {
+		t.Fatalf("unexpected time entry %+v", moved)
+	}
+}
+
+func TestE2EEditSavesNothingWithoutConfirmation(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 3600})
+
+	out := runCLI(t, "edit", "1", "--day", "2024-03-04", "--duration", "2h")
+	if !strings.Contains(out, "Nothing saved") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if duration := env.server.TimeEntries()[0].Duration; duration != 3600 {
+		t.Fatalf("expected the duration to stay unchanged, got %d", duration)
+	}
+}
+
+func TestE2EDeleteRemovesSelectedEntry(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 3600, Sorting: 1, Comment: "Keep"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-04", Duration: 1800, Sorting: 2, Comment: "Remove"})
+
+	out := runCLI(t, "delete", "2", "--day", "2024-03-04")
+	if !strings.Contains(out, "Remove") || !strings.Contains(out, "Nothing deleted") || len(env.server.TimeEntries()) != 2 {
+		t.Fatalf("expected nothing to be deleted without confirmation, got %q", out)
+	}
+
+	out = runCLI(t, "delete", "2", "--day", "2024-03-04", "--yes")
+	if !strings.Contains(out, "Deleted 1 time entry") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].Comment != "Keep" {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+}
+
+func TestE2EDeleteEmptyEntries(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 3600, Sorting: 1})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-04", Duration: 0, Sorting: 2})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-04", Duration: 20, Sorting: 3})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-05", Duration: 0, Sorting: 1})
+
+	out := runCLI(t, "delete", "--empty", "--day", "2024-03-04", "-y")
+	if !strings.Contains(out, "Deleted 2 time entries") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || timeEntries[0].Duration != 3600 || timeEntries[1].Day != "2024-03-05" {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+
+	out = runCLI(t, "delete", "--empty", "--day", "2024-03-04")
+	if !strings.Contains(out, "No empty time entries to delete") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestE2EUndoRevertsMistypedStart(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1", "Feature", "-amend")
+	runCLI(t, "start", "p2")
+
+	out := runCLI(t, "undo")
+	for _, expected := range []string{"Undoing", "start p2", "Deleted the created time entry of", "Restarted"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output %q", expected, out)
+		}
+	}
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].ProjectId != env.projects[0].Id || !timeEntries[0].Running || timeEntries[0].Comment != "- Feature" {
+		t.Fatalf("unexpected time entries after undo %+v", timeEntries)
+	}
+
+	runCLI(t, "undo")
+	if timeEntries := env.server.TimeEntries(); len(timeEntries) != 0 {
+		t.Fatalf("expected the first start to be undone too, got %+v", timeEntries)
+	}
+
+	out = runCLI(t, "undo")
+	if !strings.Contains(out, "Nothing to undo") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestE2EUndoRestoresEditedAndDeletedEntries(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 3600, Sorting: 1, Comment: "Original"})
+
+	runCLI(t, "edit", "1", "--day", "2024-03-04", "--duration", "2h", "-m", "Changed", "--yes")
+	runCLI(t, "undo")
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].Duration != 3600 || timeEntries[0].Comment != "Original" {
+		t.Fatalf("unexpected time entries after undoing edit %+v", timeEntries)
+	}
+
+	runCLI(t, "delete", "1", "--day", "2024-03-04", "--yes")
+	runCLI(t, "undo")
+	timeEntries = env.server.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].Duration != 3600 || timeEntries[0].Comment != "Original" || timeEntries[0].Day != "2024-03-04" {
+		t.Fatalf("unexpected time entries after undoing delete %+v", timeEntries)
+	}
+}
+
+func TestE2EResumeRestartsMostRecentlyStoppedEntry(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	runCLI(t, "start", "p2")
+	runCLI(t, "start", "p1")
+	runCLI(t, "stop")
+	out := runCLI(t, "prompt", "{{if not .Running}}idle{{end}}")
+	if out != "idle\n" {
+		t.Fatalf("unexpected prompt %q", out)
+	}
+	if state, _ := ReadState(); state.Last == nil || state.Last.Alias != "p1" {
+		t.Fatalf("expected p1 as the last entry in the state, got %+v", state.Last)
+	}
+
+	runCLI(t, "resume")
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || !timeEntries[0].Running || timeEntries[1].Running {
+		t.Fatalf("expected the p1 entry to be resumed: %+v", timeEntries)
+	}
+}
+
+func TestE2EResumeRestartsLastStoppedEntry(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1", "First", "-amend")
+	runCLI(t, "start", "p2", "Second", "-amend")
+	runCLI(t, "stop")
+
+	out := runCLI(t, "resume")
+	if !strings.Contains(out, "Resumed existing time entry") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || timeEntries[0].Running || !timeEntries[1].Running || timeEntries[1].Comment != "- Second" {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+
+	out = runCLI(t, "start")
+	if !strings.Contains(out, "is running already") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestE2EResumeContinuesPreviousWorkingDay(t *testing.T) {
+	env := setupE2E(t)
+	previousDay := ScheduleConfig{}.PreviousWorkingDay(time.Now()).Format(DayFormat)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: previousDay, Duration: 3600, Sorting: 1})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: previousDay, Duration: 3600, Sorting: 2, TaskId: 9, Comment: "- Migration"})
+
+	out := runCLI(t, "start")
+	if !strings.Contains(out, "from "+previousDay) {
+		t.Fatalf("unexpected output %q", out)
+	}
+	timeEntries := env.server.TimeEntries()
+	resumed := timeEntries[len(timeEntries)-1]
+	if resumed.Day != time.Now().Format(DayFormat) || !resumed.Running || resumed.ProjectId != env.projects[1].Id || resumed.TaskId != 9 || resumed.Comment != "- Migration" {
+		t.Fatalf("unexpected time entry %+v", resumed)
+	}
+}
+
+func TestE2ENoteEditsRunningComment(t *testing.T) {
+	env := setupE2E(t)
+
+	out := runCLI(t, "note", "Nothing running")
+	if !strings.Contains(out, "No time entry is running") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	runCLI(t, "start", "p1", "First", "-amend")
+	out = runCLI(t, "note", "Second")
+	if !strings.Contains(out, "- First\n- Second") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	runCLI(t, "note", "--remove-last")
+	if comment := env.server.TimeEntries()[0].Comment; comment != "- First" {
+		t.Fatalf("unexpected comment %q after removing the last bullet", comment)
+	}
+
+	runCLI(t, "note", "--replace", "Rewritten")
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 1 || timeEntries[0].Comment != "Rewritten" || !timeEntries[0].Running {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+}
+
+func TestE2EOutputFormats(t *testing.T) {
+	env := setupE2E(t)
+
+	out := runCLI(t, "today", "--output", "json")
+	if strings.TrimSpace(out) != "[]" {
+		t.Fatalf("expected an empty JSON list, got %q", out)
+	}
+
+	runCLI(t, "start", "p1", "Feature", "-amend")
+	out = runCLI(t, "today", "-o", "json")
+	var timeEntries []map[string]any
+	if err := json.Unmarshal([]byte(out), &timeEntries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(timeEntries) != 1 {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+	entry := timeEntries[0]
+	if entry["alias"] != "p1" || entry["projectName"] != "Project One" || entry["project"] != float64(env.projects[0].Id) ||
+		entry["task"] != float64(7) || entry["running"] != true || entry["comment"] != "- Feature" || entry["day"] != time.Now().Format(DayFormat) {
+		t.Fatalf("unexpected time entry %+v", entry)
+	}
+	if _, ok := entry["duration"].(float64); !ok {
+		t.Fatalf("expected the duration in seconds, got %+v", entry)
+	}
+
+	out = runCLI(t, "projects", "alias", "--output", "csv")
+	expected := fmt.Sprintf("id,name,alias,defaultTaskId\n%d,Project One,p1,7\n%d,Project Two,p2,7\n", env.projects[0].Id, env.projects[1].Id)
+	if out != expected {
+		t.Fatalf("unexpected CSV %q", out)
+	}
+}
+
+func TestE2EFormatTemplate(t *testing.T) {
+	env := setupE2E(t)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: "2024-03-04", Duration: 5400, Comment: "Planning"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: "2024-03-04", Duration: 900, Sorting: 1})
+
+	out := runCLI(t, "show", "2024-03-04", "--format", "{{.Alias}} {{.Duration | hm}} {{.Comment}}")
+	if out != "p1 01h 30m Planning\np2 00h 15m \n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	out = runCLI(t, "projects", "alias", "--format", "{{.Alias}}={{.Name}}")
+	if out != "p1=Project One\np2=Project Two\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestE2EPromptReadsStateWrittenByStartAndStop(t *testing.T) {
+	env := setupE2E(t)
+
+	if out := runCLI(t, "prompt"); out != "\n" {
+		t.Fatalf("expected an empty prompt without state, got %q", out)
+	}
+
+	runCLI(t, "start", "p1", "Feature")
+	out := runCLI(t, "prompt", "{{.Alias}} {{.ProjectName}} {{.Comment}} {{.Elapsed | clock}}")
+	if out != "p1 Project One Feature 0:00\n" {
+		t.Fatalf("unexpected prompt %q", out)
+	}
+
+	runCLI(t, "stop")
+	out = runCLI(t, "prompt", "{{if .Running}}running{{else}}idle {{.Total | clock}}{{end}}")
+	if out != "idle 0:00\n" {
+		t.Fatalf("unexpected prompt %q", out)
+	}
+
+	env.offline = true
+	runCLI(t, "start", "p2")
+	out = runCLI(t, "prompt")
+	if out != "⏱ p2 0:00\n" {
+		t.Fatalf("expected the offline start in the prompt, got %q", out)
+	}
+}
+
+func TestE2EPromptReadsStateWrittenByOtherCommands(t *testing.T) {
+	env := setupE2E(t)
+	format := "{{if .Running}}{{.Alias}} {{.Comment}}{{else}}idle{{end}}"
+
+	runCLI(t, "start", "p1", "Feature")
+	runCLI(t, "note", "Review")
+	if out := runCLI(t, "prompt", format); out != "p1 Feature\n- Review\n" {
+		t.Fatalf("expected the note in the prompt, got %q", out)
+	}
+
+	runCLI(t, "stop")
+	runCLI(t, "undo")
+	if out := runCLI(t, "prompt", format); out != "p1 Feature\n- Review\n" {
+		t.Fatalf("expected the timer restarted by undo in the prompt, got %q", out)
+	}
+
+	runCLI(t, "stop")
+	env.offline = true
+	runCLI(t, "resume")
+	if out := runCLI(t, "prompt", format); out != "p1 Feature\n- Review\n" {
+		t.Fatalf("expected the offline resume in the prompt, got %q", out)
+	}
+}
+
+func TestE2EBarTogglesTimer(t *testing.T) {
+	env := setupE2E(t)
+
+	runCLI(t, "start", "p1")
+	var status barStatus
+	if err := json.Unmarshal([]byte(runCLI(t, "bar")), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Class != BarClassRunning || !strings.HasPrefix(status.Text, "⏱ p1 0:00") {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	out := runCLI(t, "bar", "polybar", "--toggle")
+	if out != "⏸ 0:00\n" || env.server.TimeEntries()[0].Running {
+		t.Fatalf("expected the timer to be stopped, got %q", out)
+	}
+
+	t.Setenv("BLOCK_BUTTON", "1")
+	out = runCLI(t, "bar", "i3blocks")
+	if !strings.HasPrefix(out, "⏱ p1 0:00") || !env.server.TimeEntries()[0].Running {
+		t.Fatalf("expected the timer to be resumed, got %q", out)
+	}
+}
+
+func TestE2EStandupGroupsCommentsByProject(t *testing.T) {
+	env := setupE2E(t)
+	now := time.Now()
+	previous := ScheduleConfig{}.PreviousWorkingDay(now).Format(DayFormat)
+	today := now.Format(DayFormat)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: previous, Duration: 5400, Comment: "- Feature ABC\n- Review"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: previous, Duration: 900, Sorting: 1})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: previous, Duration: 1800, Sorting: 2, Comment: "- feature abc"})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: today, Duration: 600, Comment: "- Deploy"})
+
+	out := runCLI(t, "standup", "--durations")
+	expected := "- Project One (2h)\n  - Feature ABC\n  - Review\n- Project Two (15m)\n\n**Today**\n- Project One (10m)\n  - Deploy\n"
+	if !strings.HasPrefix(out, "**") || !strings.HasSuffix(out, expected) {
+		t.Fatalf("unexpected standup %q", out)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+// ErrTimeEntryNotFound is returned when no time entry matches the given
+// position or ID.
+var ErrTimeEntryNotFound = errors.New("time entry not found")
+
+func EditCommand() {
+	var args struct {
+		Position int    `cli:"position, The number of the entry in the today or show listing of the day, 1 is the first one"`
+		Id       int    `cli:"--id, The ID of the time entry, instead of its position"`
+		Day      string `cli:"-d, --day, The day of the listing the position refers to" default:"today"`
+		Duration string `cli:"--duration, The new duration, e.g. 1h30m, or +15m and -15m to add or take off time"`
+		Comment  string `cli:"-m, --comment, The new comment"`
+		Project  string `cli:"-p, --project, The alias of the new project"`
+		Task     int    `cli:"--task, The ID of the new task (default: the DefaultTaskId of the new project)"`
+		MoveTo   string `cli:"--move-to, The day to move the entry to, e.g. yesterday or 2026-10-01"`
+		Yes      bool   `cli:"-y, --yes, Save without asking for confirmation"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	if args.Duration == "" && args.Comment == "" && args.Project == "" && args.Task == 0 && args.MoveTo == "" {
+		fmt.Printf("Nothing to change. Run %s'help edit'%s to see what can be changed.\n", chalk.Cyan, chalk.Reset)
+		os.Exit(ExitInvalidInput)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	before, err := selectTimeEntry(client, args.Position, args.Id, args.Day)
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	after := before
+	if args.Duration != "" {
+		after.Duration, err = applyDurationInput(before.Duration, args.Duration)
+		if err != nil {
+			fmt.Println(chalk.Red.Color(err.Error()))
+			os.Exit(ExitInvalidInput)
+		}
+	}
+	if args.Comment != "" {
+		after.Comment = args.Comment
+	}
+	if args.Project != "" {
+		project, ok := findProjectByAlias(args.Project)
+		if !ok {
+			fmt.Printf("Project alias %s'%s'%s not found 😱\nRun the %s'help projects alias'%s command to learn how to set an alias.\n", chalk.Red, args.Project, chalk.Reset, chalk.Cyan, chalk.Reset)
+			os.Exit(ExitInvalidInput)
+		}
+		after.ProjectId = project.Id
+		after.TaskId = project.DefaultTaskId
+	}
+	if args.Task != 0 {
+		after.TaskId = args.Task
+	}
+	if args.MoveTo != "" {
+		day, err := ParseDate(args.MoveTo, time.Now())
+		if err != nil {
+			fmt.Println(chalk.Red.Color(err.Error()))
+			os.Exit(ExitInvalidInput)
+		}
+		if day.Format(DayFormat) != before.Day {
+			timeEntries, err := client.GetTimeEntriesForDay(day.Format(DayFormat))
+			if err != nil {
+				exitOnApiError(err)
+			}
+			after.Day = day.Format(DayFormat)
+			after.Sorting = nextSorting(timeEntries)
+		}
+	}
+
+	changed, err := printTimeEntryDiff(before, after, NewProjectNames(client))
+	if err != nil {
+		exitOnApiError(err)
+	}
+	if !changed {
+		fmt.Println("Nothing changed")
+		return
+	}
+
+	if !args.Yes && !confirm("Save these changes?") {
+		fmt.Println("Nothing saved")
+		return
+	}
+
+	err = recordAction(client, fmt.Sprintf("edit time entry %d", before.Id), func() error {
+		client.Action.Updated(before)
+		return client.UpdateTimeEntry(after)
+	})
+	if err != nil {
+		exitOnApiError(err)
+	}
+	fmt.Println(chalk.Green.Color("Saved"))
+}
+
+// selectTimeEntry returns the time entry with the given ID or, without ID, the
+// one at the given position in the listing of the day, starting at 1.
+func selectTimeEntry(client *AerionClient, position int, id int, dayExpr string) (aerion.TimeEntry, error) {
+	if id != 0 {
+		return client.GetTimeEntry(id)
+	}
+	if position < 1 {
+		return aerion.TimeEntry{}, errors.New("select a time entry by its position, e.g. 1 for the first one, or by --id")
+	}
+
+	day, err := ParseDate(dayExpr, time.Now())
+	if err != nil {
+		return aerion.TimeEntry{}, err
+	}
+	timeEntries, err := client.GetTimeEntriesForDay(day.Format(DayFormat))
+	if err != nil {
+		return aerion.TimeEntry{}, err
+	}
+	if position > len(timeEntries) {
+		return aerion.TimeEntry{}, fmt.Errorf("%w: there is no entry #%d on %s, it has %d", ErrTimeEntryNotFound, position, day.Format(DayFormat), len(timeEntries))
+	}
+	return timeEntries[position-1], nil
+}
+
+// applyDurationInput returns the new duration for a duration input, which is
+// either absolute or, with a leading + or -, relative to the current one.
+func applyDurationInput(current int, input string) (int, error) {
+	sign := 0
+	if strings.HasPrefix(input, "+") {
+		sign = 1
+	} else if strings.HasPrefix(input, "-") {
+		sign = -1
+	}
+	if sign == 0 {
+		return ParseDurationInput(input)
+	}
+
+	delta, err := ParseDurationInput(input[1:])
+	if err != nil {
+		return 0, err
+	}
+	return max(current+sign*delta, 0), nil
+}
+
+// printTimeEntryDiff prints the fields that differ between two versions of a
+// time entry and reports whether there are any.
+func printTimeEntryDiff(before aerion.TimeEntry, after aerion.TimeEntry, names *ProjectNames) (bool, error) {
+	beforeProject, err := names.Display(before.ProjectId)
+	if err != nil {
+		return false, err
+	}
+	afterProject, err := names.Display(after.ProjectId)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf("Time entry %d of %s on %s:\n", before.Id, beforeProject, before.Day)
+
+	changed := false
+	printChange := func(field string, from string, to string) {
+		if from == to {
+			return
+		}
+		changed = true
+		fmt.Printf("  %-9s %s → %s\n", field, chalk.Red.Color(from), chalk.Green.Color(to))
+	}
+	printChange("project", beforeProject, afterProject)
+	printChange("task", strconv.Itoa(before.TaskId), strconv.Itoa(after.TaskId))
+	printChange("day", before.Day, after.Day)
+	printChange("duration", formatHoursMinutes(before.Duration), formatHoursMinutes(after.Duration))
+	printChange("comment", strings.ReplaceAll(before.Comment, "\n", " "), strings.ReplaceAll(after.Comment, "\n", " "))
+
+	return changed, nil
+}
+
+// confirm asks a yes/no question on stdin. Anything but yes, including a
+// closed stdin, is taken as no.
+func confirm(question string) bool {
+	fmt.Printf("%s (y/n) ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
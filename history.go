@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+// Kinds of changes recorded in an action.
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+const (
+	HistoryFileName = "history.jsonl"
+	// MaxHistoryLength is the number of actions kept for undo.
+	MaxHistoryLength = 20
+)
+
+// Change is a time entry as it was before a command changed it. For created
+// entries, it's the entry as it was created.
+type Change struct {
+	Kind      string           `json:"kind"`
+	TimeEntry aerion.TimeEntry `json:"timeEntry"`
+}
+
+// Action holds the changes of one mutating command, so the undo command can
+// revert them.
+type Action struct {
+	Command string    `json:"command"`
+	At      time.Time `json:"at"`
+	Changes []Change  `json:"changes"`
+}
+
+// NewAction starts recording the changes of a command.
+func NewAction(command string) *Action {
+	return &Action{Command: command, At: time.Now()}
+}
+
+func (a *Action) String() string {
+	return a.At.Format("2006-01-02 15:04") + " " + a.Command
+}
+
+// Created records a time entry the command created. A nil action records
+// nothing.
+func (a *Action) Created(timeEntry aerion.TimeEntry) {
+	a.record(ChangeCreated, timeEntry)
+}
+
+// Updated records the state of a time entry before the command changed it.
+func (a *Action) Updated(before aerion.TimeEntry) {
+	a.record(ChangeUpdated, before)
+}
+
+// Deleted records a time entry the command deleted.
+func (a *Action) Deleted(before aerion.TimeEntry) {
+	a.record(ChangeDeleted, before)
+}
+
+func (a *Action) record(kind string, timeEntry aerion.TimeEntry) {
+	if a == nil {
+		return
+	}
+	// only the first state matters when a command changes an entry twice
+	for _, change := range a.Changes {
+		if change.TimeEntry.Id == timeEntry.Id {
+			return
+		}
+	}
+	a.Changes = append(a.Changes, Change{Kind: kind, TimeEntry: timeEntry})
+}
+
+func GetHistoryPath() string {
+	return filepath.Join(os.Getenv("HOME"), WorklowFolderPath, HistoryFileName)
+}
+
+// ReadHistory returns the recorded actions, oldest first.
+func ReadHistory() ([]Action, error) {
+	return readJSONLines[Action](GetHistoryPath())
+}
+
+// WriteHistory replaces the recorded actions, keeping only the latest
+// MaxHistoryLength ones. An empty history removes the file.
+func WriteHistory(history []Action) error {
+	if len(history) == 0 {
+		err := os.Remove(GetHistoryPath())
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	history = history[max(len(history)-MaxHistoryLength, 0):]
+
+	err := os.MkdirAll(filepath.Join(os.Getenv("HOME"), WorklowFolderPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	for _, action := range history {
+		line, err := json.Marshal(action)
+		if err != nil {
+			return err
+		}
+		content = append(append(content, line...), '\n')
+	}
+
+	return os.WriteFile(GetHistoryPath(), content, 0644)
+}
+
+// saveAction adds an action to the history, unless it didn't change anything.
+// The history is only a convenience, so failing to write it doesn't fail the
+// command.
+func saveAction(action *Action) {
+	if action == nil || len(action.Changes) == 0 {
+		return
+	}
+
+	history, err := ReadHistory()
+	if err == nil {
+		err = WriteHistory(append(history, *action))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, chalk.Yellow.Color("Couldn't record the changes for undo: "+err.Error()))
+	}
+}
+
+// recordAction runs fn while recording its changes as an action for undo, and
+// writes the state for the prompt once it succeeded.
+func recordAction(client *AerionClient, command string, fn func() error) error {
+	client.Action = NewAction(command)
+	defer func() {
+		saveAction(client.Action)
+		client.Action = nil
+	}()
+
+	err := fn()
+	if err == nil {
+		refreshState(client)
+	}
+	return err
+}
+
+// undoAction reverts the changes of an action, latest first. Entries that were
+// running before are restarted with the time passed since the action added to
+// them, as if they had never been stopped. Reverted changes are removed from
+// the action, so a failed undo can be continued.
+func undoAction(client *AerionClient, action *Action, names *ProjectNames) error {
+	elapsed := int(time.Since(action.At).Seconds())
+
+	for len(action.Changes) > 0 {
+		change := action.Changes[len(action.Changes)-1]
+		timeEntry := change.TimeEntry
+		project, err := names.Display(timeEntry.ProjectId)
+		if err != nil {
+			return err
+		}
+
+		switch change.Kind {
+		case ChangeCreated:
+			err := client.DeleteTimeEntry(timeEntry.Id)
+			var apiErr *aerion.APIError
+			if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+				err = nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Deleted the created time entry of %s%s%s\n", chalk.Green, project, chalk.Reset)
+		case ChangeUpdated:
+			if timeEntry.Running {
+				timeEntry.Duration += max(elapsed, 0)
+			}
+			err := client.UpdateTimeEntry(timeEntry)
+			if err != nil {
+				return err
+			}
+			if timeEntry.Running {
+				fmt.Printf("Restarted %s%s%s at %s\n", chalk.Green, project, chalk.Reset, formatHoursMinutes(timeEntry.Duration))
+			} else {
+				fmt.Printf("Restored %s%s%s to %s\n", chalk.Green, project, chalk.Reset, formatHoursMinutes(timeEntry.Duration))
+			}
+		case ChangeDeleted:
+			if timeEntry.Running {
+				timeEntry.Duration += max(elapsed, 0)
+			}
+			_, err := client.CreateTimeEntry(aerion.NewTimeEntry{
+				ProjectId:    timeEntry.ProjectId,
+				Day:          timeEntry.Day,
+				Duration:     timeEntry.Duration,
+				Sorting:      timeEntry.Sorting,
+				Running:      timeEntry.Running,
+				Comment:      timeEntry.Comment,
+				TaskId:       timeEntry.TaskId,
+				TrackingType: timeEntry.TrackingType,
+				UserId:       timeEntry.UserId,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Recreated the deleted time entry of %s%s%s\n", chalk.Green, project, chalk.Reset)
+		default:
+			return fmt.Errorf("unknown change '%s' in %s", change.Kind, GetHistoryPath())
+		}
+
+		action.Changes = action.Changes[:len(action.Changes)-1]
+	}
+
+	return nil
+}
+
+func UndoCommand() {
+	_, err := mcli.Parse(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	history, err := ReadHistory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, chalk.Red.Color("The undo history is broken: "+err.Error()))
+		os.Exit(ExitError)
+	}
+	if len(history) == 0 {
+		fmt.Println("Nothing to undo")
+		return
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	action := &history[len(history)-1]
+	fmt.Printf("Undoing '%s'\n", action)
+	undoErr := undoAction(client, action, NewProjectNames(client))
+	if len(action.Changes) == 0 {
+		history = history[:len(history)-1]
+	}
+
+	err = WriteHistory(history)
+	if err != nil {
+		panic(err)
+	}
+	if undoErr != nil {
+		exitOnApiError(undoErr)
+	}
+	refreshState(client)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+// Operations that can be recorded in the offline journal.
+const (
+	JournalStart  = "start"
+	JournalStop   = "stop"
+	JournalResume = "resume"
+)
+
+const (
+	JournalFileName        = "journal.jsonl"
+	SkippedJournalFileName = "journal.skipped.jsonl"
+)
+
+// JournalEntry is a mutating operation that couldn't be sent to the API
+// because it was unreachable. At is the local time the user ran the command,
+// which is used to compute the correct durations when the operation is
+// replayed.
+type JournalEntry struct {
+	Operation string    `json:"operation"`
+	Alias     string    `json:"alias,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Amend     bool      `json:"amend,omitempty"`
+	At        time.Time `json:"at"`
+	// Error is why Aerion rejected the operation, for skipped ones.
+	Error string `json:"error,omitempty"`
+}
+
+func (e JournalEntry) String() string {
+	description := e.Operation
+	if e.Alias != "" {
+		description += " " + e.Alias
+	}
+	if e.Comment != "" {
+		description += fmt.Sprintf(" %q", e.Comment)
+	}
+	return e.At.Format("2006-01-02 15:04") + " " + description
+}
+
+func GetJournalPath() string {
+	return filepath.Join(os.Getenv("HOME"), WorklowFolderPath, JournalFileName)
+}
+
+// GetSkippedJournalPath returns the file of the offline operations that Aerion
+// rejected when they were synced. They are kept there until they are dropped.
+func GetSkippedJournalPath() string {
+	return filepath.Join(os.Getenv("HOME"), WorklowFolderPath, SkippedJournalFileName)
+}
+
+// ReadJournal returns the pending offline operations, oldest first.
+func ReadJournal() ([]JournalEntry, error) {
+	return readJSONLines[JournalEntry](GetJournalPath())
+}
+
+// WriteJournal replaces the pending offline operations. An empty journal
+// removes the file.
+func WriteJournal(journal []JournalEntry) error {
+	return writeJournalFile(GetJournalPath(), journal)
+}
+
+// ReadSkippedJournal returns the skipped offline operations, oldest first.
+func ReadSkippedJournal() ([]JournalEntry, error) {
+	return readJSONLines[JournalEntry](GetSkippedJournalPath())
+}
+
+func writeJournalFile(path string, journal []JournalEntry) error {
+	if len(journal) == 0 {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	err := os.MkdirAll(filepath.Join(os.Getenv("HOME"), WorklowFolderPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	for _, entry := range journal {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		content = append(append(content, line...), '\n')
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+func AppendJournalEntry(entry JournalEntry) error {
+	journal, err := ReadJournal()
+	if err != nil {
+		return err
+	}
+
+	return WriteJournal(append(journal, entry))
+}
+
+// runOrRecord runs a mutating operation against the API. If the API is
+// unreachable, or earlier offline operations can't be synced yet, the
+// operation is recorded in the journal instead, to be replayed by the sync
+// command.
+func runOrRecord(client *AerionClient, entry JournalEntry) {
+	recorded, err := tryRunOrRecord(client, entry)
+	if recorded {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, chalk.Red.Color(fmt.Sprintf("Aerion is unreachable and '%s' couldn't be recorded: %s", entry, err)))
+			os.Exit(ExitError)
+		}
+		fmt.Println(chalk.Yellow.Color("Aerion is unreachable. Recorded '" + entry.String() + "' to be synced later."))
+		fmt.Printf("Run the %s'sync'%s command once you are back online.\n", chalk.Cyan, chalk.Reset)
+		return
+	}
+	if err != nil {
+		exitOnApiError(err)
+	}
+}
+
+// tryRunOrRecord is runOrRecord for callers that report the outcome
+// themselves. It reports whether the operation was recorded in the journal.
+// Pending operations that Aerion rejects are skipped, so they don't block the
+// new one.
+func tryRunOrRecord(client *AerionClient, entry JournalEntry) (bool, error) {
+	journal, err := ReadJournal()
+	if err != nil {
+		return false, err
+	}
+
+	if len(journal) > 0 {
+		err = syncJournal(client, true)
+	}
+	if err == nil {
+		err = replayJournalEntry(client, entry)
+	}
+
+	if aerion.IsOffline(err) {
+		err = AppendJournalEntry(entry)
+		if err == nil {
+			recordStateOffline(entry)
+		}
+		return true, err
+	}
+	return false, err
+}
+
+// syncJournal replays the pending offline operations in order. Operations are
+// removed from the journal as soon as they have been replayed, so a failing
+// sync can be resumed later. With skipRejected, operations that Aerion rejects
+// are moved to the skipped journal instead of stopping the sync.
+func syncJournal(client *AerionClient, skipRejected bool) error {
+	journal, err := ReadJournal()
+	if err != nil {
+		return err
+	}
+
+	for len(journal) > 0 {
+		fmt.Printf("Syncing '%s'\n", journal[0])
+		err := replayJournalEntry(client, journal[0])
+		if err != nil && !(skipRejected && isRejected(err)) {
+			return err
+		}
+		if err != nil {
+			err = skipJournalEntry(journal[0], err)
+			if err != nil {
+				return err
+			}
+		}
+
+		journal = journal[1:]
+		err = WriteJournal(journal)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isRejected reports whether replaying an operation failed because of the
+// operation itself, e.g. an alias that was removed or a locked day, rather
+// than because Aerion or the login isn't available, which would fail any
+// other operation too.
+func isRejected(err error) bool {
+	var apiErr *aerion.APIError
+	if errors.As(err, &apiErr) {
+		return !apiErr.IsAuth() && !apiErr.IsServer() && apiErr.StatusCode != http.StatusTooManyRequests
+	}
+	return !aerion.IsOffline(err) && !isLoginRequired(err)
+}
+
+// skipJournalEntry sets a rejected operation aside in the skipped journal and
+// tells the user about it.
+func skipJournalEntry(entry JournalEntry, rejection error) error {
+	skipped, err := ReadSkippedJournal()
+	if err != nil {
+		return err
+	}
+	message, _ := describeApiError(rejection)
+	entry.Error = message
+	err = writeJournalFile(GetSkippedJournalPath(), append(skipped, entry))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, chalk.Yellow.Color(fmt.Sprintf("Skipped '%s': %s", entry, message)))
+	fmt.Fprintf(os.Stderr, "It's kept in %s, run %s'sync --drop'%s to discard it.\n", GetSkippedJournalPath(), chalk.Cyan, chalk.Reset)
+	return nil
+}
+
+// replayJournalEntry runs a start or stop operation and records its changes
+// for undo.
+func replayJournalEntry(client *AerionClient, entry JournalEntry) error {
+	command := entry.Operation
+	if entry.Alias != "" {
+		command += " " + entry.Alias
+	}
+	return recordAction(client, command, func() error {
+		return runJournalEntry(client, entry)
+	})
+}
+
+func runJournalEntry(client *AerionClient, entry JournalEntry) error {
+	switch entry.Operation {
+	case JournalStart:
+		project, ok := findProjectByAlias(entry.Alias)
+		if !ok {
+			return fmt.Errorf("project alias '%s' not found", entry.Alias)
+		}
+		return startProject(client, project, entry.Comment, entry.Amend, entry.At)
+	case JournalStop:
+		return stopTimeEntries(client, entry.At)
+	case JournalResume:
+		return resumeLastTimeEntry(client, entry.At)
+	}
+	return fmt.Errorf("unknown operation '%s' in %s", entry.Operation, GetJournalPath())
+}
+
+// printPendingOperations lists the operations that still have to be synced,
+// and the ones that were skipped.
+func printPendingOperations() {
+	journal, err := ReadJournal()
+	if err == nil && len(journal) > 0 {
+		fmt.Println()
+		fmt.Printf("%sPending offline operations%s (run %s'sync'%s to send them to Aerion):\n", chalk.Yellow, chalk.Reset, chalk.Cyan, chalk.Reset)
+		for _, entry := range journal {
+			fmt.Printf("  %s⏸ %s%s\n", chalk.Yellow, entry, chalk.Reset)
+		}
+	}
+
+	skipped, err := ReadSkippedJournal()
+	if err == nil && len(skipped) > 0 {
+		fmt.Println()
+		fmt.Printf("%sSkipped offline operations%s (run %s'sync --drop'%s to discard them):\n", chalk.Red, chalk.Reset, chalk.Cyan, chalk.Reset)
+		for _, entry := range skipped {
+			fmt.Printf("  %s✗ %s: %s%s\n", chalk.Red, entry, entry.Error, chalk.Reset)
+		}
+	}
+}
+
+func SyncCommand() {
+	var args struct {
+		Skip bool `cli:"--skip, Set operations that Aerion rejects aside and sync the remaining ones"`
+		Drop bool `cli:"--drop, Discard the operations that were set aside"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	if args.Drop {
+		skipped, err := ReadSkippedJournal()
+		if err == nil {
+			err = writeJournalFile(GetSkippedJournalPath(), nil)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, chalk.Red.Color(err.Error()))
+			os.Exit(ExitError)
+		}
+		fmt.Printf("Dropped %d skipped operations\n", len(skipped))
+		return
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	journal, err := ReadJournal()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, chalk.Red.Color("The offline journal is broken: "+err.Error()))
+		os.Exit(ExitError)
+	}
+	if len(journal) == 0 {
+		fmt.Println("Nothing to sync")
+		return
+	}
+
+	err = syncJournal(client, args.Skip)
+	if aerion.IsOffline(err) {
+		remaining, _ := ReadJournal()
+		fmt.Println(chalk.Yellow.Color(fmt.Sprintf("Aerion is still unreachable. %d operations are left to sync.", len(remaining))))
+		os.Exit(ExitNetworkError)
+	}
+	if err != nil {
+		fmt.Printf("Syncing failed. The remaining operations are kept in %s%s%s.\n", chalk.Cyan, GetJournalPath(), chalk.Reset)
+		if isRejected(err) {
+			fmt.Printf("Run %s'sync --skip'%s to set the failing one aside.\n", chalk.Cyan, chalk.Reset)
+		}
+		exitOnApiError(err)
+	}
+
+	refreshState(client)
+	fmt.Println(chalk.Green.Color("All offline operations are synced"))
+}
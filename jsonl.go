@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// readJSONLines reads a file with one JSON value per line, like the history
+// and the journal. A missing file has no values. The last line is skipped if
+// it's cut off, as left by a crash while the file was written.
+func readJSONLines[T any](path string) ([]T, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var values []T
+	lines := bytes.Split(content, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var value T
+		err := json.Unmarshal(line, &value)
+		if err != nil && i == len(lines)-1 {
+			// every complete line ends with a newline
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read line %d of %s: %w", i+1, path, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadJSONLinesSkipsCutOffLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	content := `{"operation":"start","alias":"p1","at":"2026-10-07T09:00:00Z"}` + "\n" + `{"operation":"st`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, err := readJSONLines[JournalEntry](path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(journal) != 1 || journal[0].Alias != "p1" {
+		t.Fatalf("unexpected journal %+v", journal)
+	}
+}
+
+func TestReadJSONLinesReportsBrokenLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	content := `{"operation":"st` + "\n" + `{"operation":"stop","at":"2026-10-07T09:00:00Z"}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := readJSONLines[JournalEntry](path)
+	if err == nil || !strings.Contains(err.Error(), "line 1 of "+path) {
+		t.Fatalf("expected the broken line to be reported, got %v", err)
+	}
+}
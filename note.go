@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+func NoteCommand() {
+	var args struct {
+		Text       string `cli:"text, The note to add to the comment of the running time entry"`
+		Replace    bool   `cli:"-r, --replace, Replace the whole comment with the text instead of adding it as a bullet"`
+		RemoveLast bool   `cli:"-d, --remove-last, Remove the last bullet from the comment"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	if args.Replace && args.RemoveLast {
+		fmt.Println(chalk.Red.Color("--replace and --remove-last can't be combined"))
+		os.Exit(ExitInvalidInput)
+	}
+	if args.Text == "" && !args.Replace && !args.RemoveLast {
+		fmt.Printf("What's the note? Run %s'help note'%s to see how to use it.\n", chalk.Cyan, chalk.Reset)
+		os.Exit(ExitInvalidInput)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	timeEntries, err := client.GetTodaysTimeEntries()
+	if err != nil {
+		exitOnApiError(err)
+	}
+	index := -1
+	for i, timeEntry := range timeEntries {
+		if timeEntry.Running {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		fmt.Println("No time entry is running")
+		return
+	}
+
+	timeEntry := timeEntries[index]
+	switch {
+	case args.Replace:
+		timeEntry.Comment = args.Text
+	case args.RemoveLast:
+		timeEntry.Comment, err = removeLastBullet(timeEntry.Comment)
+		if err != nil {
+			fmt.Println(chalk.Red.Color(err.Error()))
+			os.Exit(ExitInvalidInput)
+		}
+	default:
+		timeEntry.Comment = appendComment(timeEntry.Comment, args.Text)
+	}
+
+	err = recordAction(client, "note", func() error {
+		client.Action.Updated(timeEntries[index])
+		return client.UpdateTimeEntry(timeEntry)
+	})
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	project, err := NewProjectNames(client).Display(timeEntry.ProjectId)
+	if err != nil {
+		exitOnApiError(err)
+	}
+	if timeEntry.Comment == "" {
+		fmt.Printf("The comment of %s%s%s is empty now\n", chalk.Green, project, chalk.Reset)
+		return
+	}
+	fmt.Printf("📝 %s%s%s:\n%s\n", chalk.Green, project, chalk.Reset, timeEntry.Comment)
+}
+
+// removeLastBullet removes the last "- " bullet, as added by appendComment,
+// from a comment.
+func removeLastBullet(comment string) (string, error) {
+	if index := strings.LastIndex(comment, "\n- "); index != -1 {
+		return comment[:index], nil
+	}
+	if strings.HasPrefix(comment, "- ") {
+		return "", nil
+	}
+	return comment, errors.New("the comment has no bullet to remove")
+}
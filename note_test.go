@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestRemoveLastBullet(t *testing.T) {
+	tests := map[string]string{
+		"- one\n- two":            "- one",
+		"- one":                   "",
+		"Ticket 42\n- one":        "Ticket 42",
+		"- one\n- two\n- three":   "- one\n- two",
+		"- one\ncontinued\n- two": "- one\ncontinued",
+	}
+	for comment, expected := range tests {
+		result, err := removeLastBullet(comment)
+		if err != nil || result != expected {
+			t.Errorf("removeLastBullet(%q) = %q, %v, expected %q", comment, result, err, expected)
+		}
+	}
+
+	if _, err := removeLastBullet("no bullets"); err == nil {
+		t.Error("expected an error for a comment without bullets")
+	}
+}
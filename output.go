@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/ttacon/chalk"
+)
+
+// Formats of the global --output flag.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputCSV  = "csv"
+	OutputTSV  = "tsv"
+	// OutputTemplate is used when a --format template is given.
+	OutputTemplate = "template"
+)
+
+// TimeEntryView is a time entry with its project resolved, as written by the
+// machine-readable outputs and passed to --format templates.
+type TimeEntryView struct {
+	aerion.TimeEntry
+	Alias       string `json:"alias"`
+	ProjectName string `json:"projectName"`
+}
+
+// outputFormat returns the format asked for with --output or --format,
+// exiting on unknown formats and invalid templates.
+func outputFormat() string {
+	if globalFlags.Format != "" {
+		_, err := parseFormatTemplate(globalFlags.Format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, chalk.Red.Color("Invalid --format template: "+err.Error()))
+			os.Exit(ExitInvalidInput)
+		}
+		return OutputTemplate
+	}
+
+	switch globalFlags.Output {
+	case "", OutputText:
+		return OutputText
+	case OutputJSON, OutputCSV, OutputTSV:
+		return globalFlags.Output
+	}
+
+	fmt.Fprintln(os.Stderr, chalk.Red.Color(fmt.Sprintf("Unknown output format '%s', use text, json, csv or tsv", globalFlags.Output)))
+	os.Exit(ExitInvalidInput)
+	return ""
+}
+
+func resolveTimeEntries(timeEntries []aerion.TimeEntry, names *ProjectNames) ([]TimeEntryView, error) {
+	views := make([]TimeEntryView, 0, len(timeEntries))
+	for _, timeEntry := range timeEntries {
+		name, err := names.Name(timeEntry.ProjectId)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, TimeEntryView{
+			TimeEntry:   timeEntry,
+			Alias:       names.Alias(timeEntry.ProjectId),
+			ProjectName: name,
+		})
+	}
+	return views, nil
+}
+
+// writeTimeEntries writes time entries in a machine-readable format.
+func writeTimeEntries(w io.Writer, format string, timeEntries []aerion.TimeEntry, names *ProjectNames) error {
+	views, err := resolveTimeEntries(timeEntries, names)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case OutputJSON:
+		return writeJSON(w, views)
+	case OutputTemplate:
+		tmpl, err := parseFormatTemplate(globalFlags.Format)
+		if err != nil {
+			return err
+		}
+		return writeTemplate(w, tmpl, views)
+	}
+
+	header := []string{"id", "day", "project", "projectName", "alias", "task", "duration", "running", "comment"}
+	var records [][]string
+	for _, view := range views {
+		records = append(records, []string{
+			strconv.Itoa(view.Id),
+			view.Day,
+			strconv.Itoa(view.ProjectId),
+			view.ProjectName,
+			view.Alias,
+			strconv.Itoa(view.TaskId),
+			strconv.Itoa(view.Duration),
+			strconv.FormatBool(view.Running),
+			view.Comment,
+		})
+	}
+	return writeRecords(w, format, header, records)
+}
+
+// writeProjects writes projects in a machine-readable format.
+func writeProjects(w io.Writer, format string, projects []ProjectConfig) error {
+	switch format {
+	case OutputJSON:
+		if projects == nil {
+			projects = []ProjectConfig{}
+		}
+		return writeJSON(w, projects)
+	case OutputTemplate:
+		tmpl, err := parseFormatTemplate(globalFlags.Format)
+		if err != nil {
+			return err
+		}
+		return writeTemplate(w, tmpl, projects)
+	}
+
+	header := []string{"id", "name", "alias", "defaultTaskId"}
+	var records [][]string
+	for _, project := range projects {
+		records = append(records, []string{
+			strconv.Itoa(project.Id),
+			project.Name,
+			project.Alias,
+			strconv.Itoa(project.DefaultTaskId),
+		})
+	}
+	return writeRecords(w, format, header, records)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeRecords writes CSV, or TSV with the same quoting rules.
+func writeRecords(w io.Writer, format string, header []string, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if format == OutputTSV {
+		writer.Comma = '\t'
+	}
+	writer.Write(header)
+	writer.WriteAll(records)
+	return writer.Error()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+)
+
+func TestWriteTimeEntriesAsCSVAndTSV(t *testing.T) {
+	names := &ProjectNames{configs: map[string]ProjectConfig{"100": {Alias: "p1", Name: "Project One", Id: 100}}}
+	timeEntries := []aerion.TimeEntry{{Id: 1, ProjectId: 100, TaskId: 7, Day: "2026-10-01", Duration: 5400, Running: true, Comment: "- a, b\n- \"c\""}}
+
+	var out bytes.Buffer
+	if err := writeTimeEntries(&out, OutputCSV, timeEntries, names); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,day,project,projectName,alias,task,duration,running,comment\n" +
+		"1,2026-10-01,100,Project One,p1,7,5400,true,\"- a, b\n- \"\"c\"\"\"\n"
+	if out.String() != expected {
+		t.Errorf("unexpected CSV %q", out.String())
+	}
+
+	out.Reset()
+	if err := writeTimeEntries(&out, OutputTSV, timeEntries[:0], names); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "id\tday\tproject\tprojectName\talias\ttask\tduration\trunning\tcomment\n" {
+		t.Errorf("unexpected TSV %q", out.String())
+	}
+}
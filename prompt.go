@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+// DefaultPromptFormat shows the running project and its time, and nothing
+// while no timer is running.
+const DefaultPromptFormat = "{{if .Running}}⏱ {{.Alias}} {{.Elapsed | clock}}{{end}}"
+
+// PromptData is what prompt format templates are executed with.
+type PromptData struct {
+	Running     bool
+	Alias       string
+	ProjectName string
+	Comment     string
+	// Elapsed is the duration of the running entry in seconds.
+	Elapsed int
+	// Total is the time booked today in seconds, including the running entry.
+	Total     int
+	UpdatedAt time.Time
+	// Stale is set when the state is older than the --stale duration.
+	Stale bool
+}
+
+// newPromptData computes the prompt as of now from the last written state.
+func newPromptData(state State, now time.Time, staleAfter time.Duration) PromptData {
+	data := PromptData{
+		UpdatedAt: state.UpdatedAt,
+		Stale:     state.UpdatedAt.IsZero() || (staleAfter > 0 && now.Sub(state.UpdatedAt) > staleAfter),
+	}
+	if state.Day == now.Format(DayFormat) {
+		data.Total = state.Total
+	}
+	if state.Running == nil {
+		return data
+	}
+
+	data.Running = true
+	data.Alias = state.Running.Alias
+	if data.Alias == "" {
+		data.Alias = state.Running.ProjectName
+	}
+	data.ProjectName = state.Running.ProjectName
+	data.Comment = state.Running.Comment
+	data.Elapsed = max(int(now.Sub(state.Running.StartedAt).Seconds()), 0)
+	if state.Day == now.Format(DayFormat) {
+		data.Total += max(int(now.Sub(state.UpdatedAt).Seconds()), 0)
+	} else {
+		// the entry started on an earlier day, today only has its time since midnight
+		data.Total = int(now.Sub(truncateToDay(now)).Seconds())
+	}
+	return data
+}
+
+func PromptCommand() {
+	var args struct {
+		Format  string        `cli:"format, Go template for the prompt, e.g. {{.Alias}} {{.Elapsed | clock}} of {{.Total | clock}} (default: $AERION_PROMPT_FORMAT or the running alias and time)"`
+		Stale   time.Duration `cli:"--stale, Age after which the state counts as stale, e.g. 5m" default:"5m"`
+		Refresh bool          `cli:"-r, --refresh, Fetch today's entries in the background when the state is stale"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	format := args.Format
+	if format == "" {
+		format = os.Getenv("AERION_PROMPT_FORMAT")
+	}
+	if format == "" {
+		format = DefaultPromptFormat
+	}
+	tmpl, err := parseFormatTemplate(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, chalk.Red.Color("Invalid prompt format: "+err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+
+	// a broken state file mustn't break the shell prompt, it's just empty
+	state, _ := ReadState()
+	data := newPromptData(state, time.Now(), args.Stale)
+	if data.Stale && args.Refresh {
+		startBackgroundRefresh()
+	}
+
+	err = tmpl.Execute(os.Stdout, data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, chalk.Red.Color("Invalid prompt format: "+err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+	fmt.Println()
+}
+
+// startBackgroundRefresh runs the today command detached, which writes the
+// state. A marker file keeps prompts drawn in quick succession from starting
+// more than one refresh per minute.
+func startBackgroundRefresh() {
+	marker := GetStatePath() + ".refresh"
+	if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) < time.Minute {
+		return
+	}
+	now := time.Now()
+	os.MkdirAll(filepath.Dir(marker), os.ModePerm)
+	if os.WriteFile(marker, nil, 0644) != nil || os.Chtimes(marker, now, now) != nil {
+		return
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(executable, "today", "--output", "json")
+	if cmd.Start() == nil {
+		cmd.Process.Release()
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/ttacon/chalk"
+)
+
+// ProjectNames resolves the name shown for a project: its alias, or its full
+// name if it has none. Projects are only fetched from the API when an entry
+// without alias shows up.
+type ProjectNames struct {
+	client   *AerionClient
+	configs  map[string]ProjectConfig
+	projects []aerion.Project
+}
+
+func NewProjectNames(client *AerionClient) *ProjectNames {
+	cfg, _ := ReadConfig()
+	return &ProjectNames{client: client, configs: cfg.Projects}
+}
+
+// Alias returns the configured alias of a project, or "" if it has none.
+func (n *ProjectNames) Alias(projectId int) string {
+	for _, project := range n.configs {
+		if project.Id == projectId {
+			return project.Alias
+		}
+	}
+	return ""
+}
+
+// Name returns the full name of a project.
+func (n *ProjectNames) Name(projectId int) (string, error) {
+	for _, project := range n.configs {
+		if project.Id == projectId && project.Name != "" {
+			return project.Name, nil
+		}
+	}
+
+	if n.projects == nil {
+		projects, err := n.client.GetProjects()
+		if err != nil {
+			return "", err
+		}
+		n.projects = projects
+	}
+	for _, project := range n.projects {
+		if project.Id == projectId {
+			return project.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// Display returns the alias of a project, or its full name if it has none.
+func (n *ProjectNames) Display(projectId int) (string, error) {
+	if alias := n.Alias(projectId); alias != "" {
+		return alias, nil
+	}
+	return n.Name(projectId)
+}
+
+// formatHoursMinutes formats a duration in seconds like "01h 05m".
+func formatHoursMinutes(seconds int) string {
+	return fmt.Sprintf("%02dh %02dm", seconds/3600, (seconds%3600)/60)
+}
+
+// formatClock formats a duration in seconds like "1:05".
+func formatClock(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/3600, (seconds%3600)/60)
+}
+
+// printTimeEntries prints one line per time entry followed by their total.
+// Numbered rows start with their position, which edit and delete accept.
+func printTimeEntries(w io.Writer, timeEntries []aerion.TimeEntry, names *ProjectNames, color bool, numbered bool) error {
+	err := printTimeEntryRows(w, timeEntries, names, color, numbered)
+	if err != nil {
+		return err
+	}
+
+	var overallTime int
+	for _, timeEntry := range timeEntries {
+		overallTime += timeEntry.Duration
+	}
+	if numbered {
+		fmt.Fprint(w, "    ")
+	}
+	printTotal(w, "total", overallTime, color)
+	return nil
+}
+
+// printTimeEntryRows prints one line per time entry, ending in its ID.
+func printTimeEntryRows(w io.Writer, timeEntries []aerion.TimeEntry, names *ProjectNames, color bool, numbered bool) error {
+	var longestComment int
+	for _, timeEntry := range timeEntries {
+		longestComment = max(longestComment, len(timeEntry.Comment))
+	}
+
+	// todo: use more colors with chalk
+	for i, timeEntry := range timeEntries {
+		timeString := formatHoursMinutes(timeEntry.Duration)
+
+		projectAlias, err := names.Display(timeEntry.ProjectId)
+		if err != nil {
+			return err
+		}
+
+		if numbered {
+			fmt.Fprintf(w, "%-4s", fmt.Sprintf("#%d", i+1))
+		}
+		comment := strings.ReplaceAll(timeEntry.Comment, "\n", " ")
+		id := fmt.Sprintf("id %d", timeEntry.Id)
+		if timeEntry.Running {
+			fmt.Fprintf(w, "%-10s | ⌛ %s | 📝 %-*s | %s\n", projectAlias, timeString, longestComment, comment, id)
+		} else {
+			if color {
+				fmt.Fprintf(w, "%-19s %s    %s %s 📝 %s %s %s\n", chalk.Dim.TextStyle(projectAlias), chalk.Dim.TextStyle("|"), chalk.Dim.TextStyle(timeString), chalk.Dim.TextStyle("|"), chalk.Dim.TextStyle(fmt.Sprintf("%-*s", longestComment, comment)), chalk.Dim.TextStyle("|"), chalk.Dim.TextStyle(id))
+			} else {
+				fmt.Fprintf(w, "%-10s |    %s | 📝 %-*s | %s\n", projectAlias, timeString, longestComment, comment, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+func printTotal(w io.Writer, label string, seconds int, color bool) {
+	if color {
+		fmt.Fprintf(w, "%-19s %s    %s\n", chalk.Dim.TextStyle(label), chalk.Dim.TextStyle("|"), chalk.Dim.TextStyle(formatHoursMinutes(seconds)))
+	} else {
+		fmt.Fprintf(w, "%-10s |    %s\n", label, formatHoursMinutes(seconds))
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+func ResumeCommand() {
+	_, err := mcli.Parse(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	runOrRecord(client, JournalEntry{
+		Operation: JournalResume,
+		At:        time.Now(),
+	})
+}
+
+// resumeLastTimeEntry sets the most recently stopped entry of the given time's
+// day running again, keeping its comment. If no stop was recorded for that day,
+// its last listed entry is resumed. Without entries on that day, the last
+// entry of the previous working day is continued in a new entry. Like in
+// startProject, the time passed since then is added to the resumed entry.
+func resumeLastTimeEntry(client *AerionClient, since time.Time) error {
+	day := since.Format(DayFormat)
+	elapsed := int(time.Since(since).Seconds())
+	names := NewProjectNames(client)
+
+	timeEntries, err := client.GetTimeEntriesForDay(day)
+	if err != nil {
+		return err
+	}
+	state, _ := ReadState()
+
+	for _, timeEntry := range timeEntries {
+		if timeEntry.Running {
+			project, err := names.Display(timeEntry.ProjectId)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s%s%s is running already\n", chalk.Green, project, chalk.Reset)
+			return nil
+		}
+	}
+
+	if timeEntry, ok := lastTimeEntry(timeEntries, state.LastStoppedId); ok {
+		project, err := names.Display(timeEntry.ProjectId)
+		if err != nil {
+			return err
+		}
+
+		client.Action.Updated(timeEntry)
+		timeEntry.Running = true
+		timeEntry.Duration += elapsed
+		err = client.UpdateTimeEntry(timeEntry)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Resumed existing time entry for %s%s%s\n", chalk.Green, project, chalk.Reset)
+		return nil
+	}
+
+	cfg, _ := ReadConfig()
+	previousDay := cfg.Schedule.PreviousWorkingDay(since).Format(DayFormat)
+	previousTimeEntries, err := client.GetTimeEntriesForDay(previousDay)
+	if err != nil {
+		return err
+	}
+	last, ok := lastTimeEntry(previousTimeEntries, state.LastStoppedId)
+	if !ok {
+		fmt.Printf("There is nothing to resume, neither today nor on %s\n", previousDay)
+		return nil
+	}
+
+	project, err := names.Display(last.ProjectId)
+	if err != nil {
+		return err
+	}
+	created, err := client.CreateTimeEntry(aerion.NewTimeEntry{
+		ProjectId:    last.ProjectId,
+		Day:          day,
+		Duration:     elapsed,
+		Sorting:      nextSorting(timeEntries),
+		Running:      true,
+		Comment:      last.Comment,
+		TaskId:       last.TaskId,
+		TrackingType: "WORK",
+		UserId:       client.UserId,
+	})
+	if err != nil {
+		fmt.Println("Error creating new time entry:")
+		return err
+	}
+	client.Action.Created(created)
+
+	fmt.Printf("Continued %s%s%s from %s in a new time entry\n", chalk.Green, project, chalk.Reset, previousDay)
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const DefaultWeeklyHours = 40
+
+// ScheduleConfig describes the hours the user is expected to work. Without
+// any settings, it's 40 hours from Monday to Friday.
+type ScheduleConfig struct {
+	// WeeklyHours are split evenly across the WorkingDays.
+	WeeklyHours float64
+	// WorkingDays are the weekdays that are worked, e.g. ["Mon", "Tue",
+	// "Thu"]. Defaults to Monday to Friday.
+	WorkingDays []string
+	// Hours sets the target of single weekdays, e.g. { Fri = 4 } for a short
+	// Friday. A weekday listed here is a working day if its hours are above
+	// zero, regardless of WorkingDays, and doesn't take a share of
+	// WeeklyHours.
+	Hours map[string]float64
+	// Start is the day the overtime balance starts to count, e.g.
+	// "2026-01-01".
+	Start string
+	// InitialBalance are the hours of overtime carried over into Start.
+	// Negative for hours that are still owed.
+	InitialBalance float64
+}
+
+// Targets returns the target seconds per weekday, indexed by time.Weekday.
+func (s ScheduleConfig) Targets() ([7]int, error) {
+	var targets [7]int
+
+	workingDays := s.WorkingDays
+	if len(workingDays) == 0 {
+		workingDays = []string{"Mon", "Tue", "Wed", "Thu", "Fri"}
+	}
+	weeklyHours := s.WeeklyHours
+	if weeklyHours == 0 {
+		weeklyHours = DefaultWeeklyHours
+	}
+
+	overridden := map[time.Weekday]float64{}
+	for name, hours := range s.Hours {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return targets, fmt.Errorf("unknown weekday '%s' in Schedule.Hours", name)
+		}
+		if hours < 0 {
+			return targets, fmt.Errorf("negative hours for '%s' in Schedule.Hours", name)
+		}
+		overridden[weekday] = hours
+	}
+
+	var sharingDays []time.Weekday
+	for _, name := range workingDays {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return targets, fmt.Errorf("unknown weekday '%s' in Schedule.WorkingDays", name)
+		}
+		if _, ok := overridden[weekday]; !ok {
+			sharingDays = append(sharingDays, weekday)
+		}
+	}
+
+	for weekday, hours := range overridden {
+		targets[weekday] = hoursToSeconds(hours)
+		weeklyHours -= hours
+	}
+	for _, weekday := range sharingDays {
+		targets[weekday] = hoursToSeconds(max(weeklyHours, 0) / float64(len(sharingDays)))
+	}
+
+	return targets, nil
+}
+
+// TargetSeconds returns how long the user is expected to work on the day.
+func (s ScheduleConfig) TargetSeconds(day time.Time) (int, error) {
+	targets, err := s.Targets()
+	return targets[day.Weekday()], err
+}
+
+// PreviousWorkingDay returns the latest working day before the given day, e.g.
+// last Friday on a Monday. Falls back to the day before if no weekday has a
+// target.
+func (s ScheduleConfig) PreviousWorkingDay(day time.Time) time.Time {
+	targets, err := s.Targets()
+	if err != nil {
+		targets, _ = ScheduleConfig{}.Targets()
+	}
+
+	day = truncateToDay(day)
+	for i := 1; i <= 7; i++ {
+		previous := day.AddDate(0, 0, -i)
+		if targets[previous.Weekday()] > 0 {
+			return previous
+		}
+	}
+	return day.AddDate(0, 0, -1)
+}
+
+func hoursToSeconds(hours float64) int {
+	return int(math.Round(hours * 3600))
+}
+
+// formatSignedHoursMinutes formats a duration that may be negative, like
+// "+01h 05m" or "-00h 30m".
+func formatSignedHoursMinutes(seconds int) string {
+	if seconds < 0 {
+		return "-" + formatHoursMinutes(-seconds)
+	}
+	return "+" + formatHoursMinutes(seconds)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule ScheduleConfig
+		expected [7]int
+	}{
+		{"default", ScheduleConfig{}, [7]int{0, 8 * 3600, 8 * 3600, 8 * 3600, 8 * 3600, 8 * 3600, 0}},
+		{"part time", ScheduleConfig{WeeklyHours: 24, WorkingDays: []string{"Mon", "Tuesday", "wed"}}, [7]int{0, 8 * 3600, 8 * 3600, 8 * 3600, 0, 0, 0}},
+		{"short friday", ScheduleConfig{WeeklyHours: 38, Hours: map[string]float64{"Fri": 6}}, [7]int{0, 8 * 3600, 8 * 3600, 8 * 3600, 8 * 3600, 6 * 3600, 0}},
+		{"day off", ScheduleConfig{WeeklyHours: 32, Hours: map[string]float64{"Wed": 0}}, [7]int{0, 8 * 3600, 8 * 3600, 0, 8 * 3600, 8 * 3600, 0}},
+		{"saturday", ScheduleConfig{Hours: map[string]float64{"Sat": 4}}, [7]int{0, 7.2 * 3600, 7.2 * 3600, 7.2 * 3600, 7.2 * 3600, 7.2 * 3600, 4 * 3600}},
+	}
+
+	for _, test := range tests {
+		targets, err := test.schedule.Targets()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if targets != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, targets)
+		}
+	}
+}
+
+func TestScheduleTargetsRejectsUnknownWeekdays(t *testing.T) {
+	if _, err := (ScheduleConfig{WorkingDays: []string{"Mon", "Funday"}}).Targets(); err == nil {
+		t.Error("expected an error for an unknown working day")
+	}
+	if _, err := (ScheduleConfig{Hours: map[string]float64{"Holiday": 2}}).Targets(); err == nil {
+		t.Error("expected an error for an unknown weekday in Hours")
+	}
+}
+
+func TestSchedulePreviousWorkingDay(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local)
+	thursday := time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)
+	partTime := ScheduleConfig{WorkingDays: []string{"Mon", "Tue", "Wed"}}
+
+	tests := []struct {
+		schedule ScheduleConfig
+		day      time.Time
+		expected string
+	}{
+		{ScheduleConfig{}, monday, "2026-10-09"},
+		{ScheduleConfig{}, thursday, "2026-10-14"},
+		{partTime, monday, "2026-10-07"},
+		{partTime, thursday, "2026-10-14"},
+		{ScheduleConfig{Hours: map[string]float64{"Fri": 0}}, monday, "2026-10-08"},
+	}
+
+	for _, test := range tests {
+		previous := test.schedule.PreviousWorkingDay(test.day)
+		if previous.Format(DayFormat) != test.expected {
+			t.Errorf("PreviousWorkingDay(%s) with %+v = %s, expected %s", test.day.Format(DayFormat), test.schedule, previous.Format(DayFormat), test.expected)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+func ShowCommand() {
+	var args struct {
+		Color bool     `cli:"-c, --color, enable colors in the output"`
+		From  string   `cli:"-f, --from, The first day to show, e.g. 2026-10-01 or last monday"`
+		To    string   `cli:"-t, --to, The last day to show (default: today if --from is given)"`
+		Range []string `cli:"range, The days to show, e.g. yesterday, last friday, last week or 2026-10-01..2026-10-07 (default: today)"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	from, to, err := parseShowRange(strings.Join(args.Range, " "), args.From, args.To, time.Now())
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	timeEntries, err := client.GetTimeEntriesBetween(from.Format(DayFormat), to.Format(DayFormat))
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	if format := outputFormat(); format != OutputText {
+		err = writeTimeEntries(os.Stdout, format, timeEntries, NewProjectNames(client))
+		if err != nil {
+			exitOnApiError(err)
+		}
+		return
+	}
+
+	if len(timeEntries) == 0 {
+		if from.Equal(to) {
+			fmt.Printf("No time entries for %s\n", from.Format(DayFormat))
+		} else {
+			fmt.Printf("No time entries between %s and %s\n", from.Format(DayFormat), to.Format(DayFormat))
+		}
+		return
+	}
+
+	names := NewProjectNames(client)
+	var overallTime int
+	days := groupTimeEntriesByDay(timeEntries)
+	for i, day := range days {
+		if i > 0 {
+			fmt.Println()
+		}
+		heading := formatDayHeading(day[0].Day)
+		if args.Color {
+			heading = chalk.Bold.TextStyle(heading)
+		}
+		fmt.Println(heading)
+
+		err = printTimeEntries(os.Stdout, day, names, args.Color, true)
+		if err != nil {
+			exitOnApiError(err)
+		}
+		for _, timeEntry := range day {
+			overallTime += timeEntry.Duration
+		}
+	}
+
+	if len(days) > 1 {
+		fmt.Println()
+		printTotal(os.Stdout, "overall", overallTime, args.Color)
+	}
+}
+
+// parseShowRange combines the positional date expression with the --from and
+// --to flags, which take precedence.
+func parseShowRange(expr string, fromExpr string, toExpr string, now time.Time) (time.Time, time.Time, error) {
+	from, to, err := ParseDateRange(expr, now)
+	if err != nil {
+		return from, to, err
+	}
+	if fromExpr != "" {
+		from, _, err = ParseDateRange(fromExpr, now)
+		if err != nil {
+			return from, to, err
+		}
+		if expr == "" {
+			to = truncateToDay(now)
+		}
+	}
+	if toExpr != "" {
+		_, to, err = ParseDateRange(toExpr, now)
+		if err != nil {
+			return from, to, err
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("%s is before %s", to.Format(DayFormat), from.Format(DayFormat))
+	}
+	return from, to, nil
+}
+
+// groupTimeEntriesByDay splits time entries that are ordered by day into one
+// slice per day.
+func groupTimeEntriesByDay(timeEntries []aerion.TimeEntry) [][]aerion.TimeEntry {
+	var days [][]aerion.TimeEntry
+	for i, timeEntry := range timeEntries {
+		if i == 0 || timeEntry.Day != timeEntries[i-1].Day {
+			days = append(days, nil)
+		}
+		days[len(days)-1] = append(days[len(days)-1], timeEntry)
+	}
+	return days
+}
+
+func formatDayHeading(day string) string {
+	date, err := time.Parse(DayFormat, day)
+	if err != nil {
+		return day
+	}
+	return date.Format("Monday, 2006-01-02")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+)
+
+// standupProject is what was done in a project on a day.
+type standupProject struct {
+	name     string
+	duration int
+	items    []string
+}
+
+// groupStandupProjects groups time entries by project, in the order the
+// projects were first worked on, merging the items of their comments.
+func groupStandupProjects(timeEntries []aerion.TimeEntry, names *ProjectNames) ([]*standupProject, error) {
+	var projects []*standupProject
+	byId := map[int]*standupProject{}
+	for _, timeEntry := range timeEntries {
+		project, ok := byId[timeEntry.ProjectId]
+		if !ok {
+			name, err := names.Name(timeEntry.ProjectId)
+			if err != nil {
+				return nil, err
+			}
+			project = &standupProject{name: name}
+			byId[timeEntry.ProjectId] = project
+			projects = append(projects, project)
+		}
+
+		project.duration += timeEntry.Duration
+		for _, item := range commentItems(timeEntry.Comment) {
+			if !containsItem(project.items, item) {
+				project.items = append(project.items, item)
+			}
+		}
+	}
+	return projects, nil
+}
+
+// commentItems splits a comment into list items. Each "- " bullet is an item,
+// with the lines up to the next bullet joined to it. Lines before the first
+// bullet are items of their own.
+func commentItems(comment string) []string {
+	var items []string
+	inBullet := false
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if item, found := strings.CutPrefix(line, "- "); found {
+			items = append(items, strings.TrimSpace(item))
+			inBullet = true
+		} else if inBullet {
+			items[len(items)-1] += " " + line
+		} else {
+			items = append(items, line)
+		}
+	}
+	return items
+}
+
+// containsItem reports whether the item is listed already, ignoring case.
+func containsItem(items []string, item string) bool {
+	for _, existing := range items {
+		if strings.EqualFold(existing, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStandupDay writes a day as a Markdown list with a bold heading. Slack
+// only understands single asterisks for bold text.
+func writeStandupDay(w io.Writer, heading string, projects []*standupProject, durations bool, slack bool) {
+	bold := "**"
+	if slack {
+		bold = "*"
+	}
+	fmt.Fprintf(w, "%s%s%s\n", bold, heading, bold)
+
+	if len(projects) == 0 {
+		fmt.Fprintln(w, "- Nothing booked")
+	}
+	for _, project := range projects {
+		line := "- " + project.name
+		if durations {
+			line += fmt.Sprintf(" (%s)", formatStandupDuration(project.duration))
+		}
+		fmt.Fprintln(w, line)
+		for _, item := range project.items {
+			fmt.Fprintln(w, "  - "+item)
+		}
+	}
+}
+
+func formatStandupDuration(seconds int) string {
+	if seconds < 60 {
+		return "0m"
+	}
+	return SecondsToHoursMinutes(seconds)
+}
+
+func StandupCommand() {
+	var args struct {
+		Durations bool `cli:"-d, --durations, Add the time booked per project"`
+		Slack     bool `cli:"--slack, Mark headings bold the way Slack does"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	cfg, _ := ReadConfig()
+	now := time.Now()
+	today := truncateToDay(now)
+	previous := cfg.Schedule.PreviousWorkingDay(now)
+
+	timeEntries, err := client.GetTimeEntriesBetween(previous.Format(DayFormat), today.Format(DayFormat))
+	if err != nil {
+		exitOnApiError(err)
+	}
+	byDay := map[string][]aerion.TimeEntry{}
+	for _, timeEntry := range timeEntries {
+		byDay[timeEntry.Day] = append(byDay[timeEntry.Day], timeEntry)
+	}
+
+	heading := previous.Format("Monday")
+	if previous.Equal(today.AddDate(0, 0, -1)) {
+		heading = "Yesterday"
+	}
+
+	names := NewProjectNames(client)
+	for i, day := range []time.Time{previous, today} {
+		projects, err := groupStandupProjects(byDay[day.Format(DayFormat)], names)
+		if err != nil {
+			exitOnApiError(err)
+		}
+		if i > 0 {
+			fmt.Println()
+			heading = "Today"
+		}
+		writeStandupDay(os.Stdout, heading, projects, args.Durations, args.Slack)
+	}
+}
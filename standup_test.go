@@ -0,0 +1,21 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommentItems(t *testing.T) {
+	tests := map[string][]string{
+		"":                            nil,
+		"- one\n- two":                {"one", "two"},
+		"Ticket 42\n- one":            {"Ticket 42", "one"},
+		"- one\ncontinued\n\n- two  ": {"one continued", "two"},
+		"Planning":                    {"Planning"},
+	}
+	for comment, expected := range tests {
+		if items := commentItems(comment); !reflect.DeepEqual(items, expected) {
+			t.Errorf("commentItems(%q) = %q, expected %q", comment, items, expected)
+		}
+	}
+}
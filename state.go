@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+)
+
+const StateFileName = "state.json"
+
+// State is a snapshot of today's time tracking, written whenever commands
+// learn about it, so the prompt command can answer without calling the API.
+type State struct {
+	UpdatedAt time.Time `json:"updatedAt"`
+	Day       string    `json:"day"`
+	// Running is the running time entry, if any.
+	Running *RunningState `json:"running,omitempty"`
+	// Total is the time booked on Day as of UpdatedAt, including the running
+	// entry.
+	Total int `json:"total"`
+	// Last is the last entry of Day, which resume continues while no timer
+	// runs.
+	Last *LastState `json:"last,omitempty"`
+	// LastStoppedId is the ID of the entry that was stopped most recently. It
+	// outlives the state fetched from the API, which can't tell.
+	LastStoppedId int `json:"lastStoppedId,omitempty"`
+}
+
+type RunningState struct {
+	Alias       string `json:"alias"`
+	ProjectName string `json:"projectName"`
+	Comment     string `json:"comment"`
+	// StartedAt is when the entry would have started if it had run without
+	// breaks, so the time passed since then is its duration.
+	StartedAt time.Time `json:"startedAt"`
+}
+
+type LastState struct {
+	Alias       string `json:"alias"`
+	ProjectName string `json:"projectName"`
+	Comment     string `json:"comment"`
+	// Duration is the time booked on the entry in seconds.
+	Duration int `json:"duration"`
+}
+
+// stopped returns the running entry as the last one, stopped at the given time.
+func (r *RunningState) stopped(at time.Time) *LastState {
+	return &LastState{
+		Alias:       r.Alias,
+		ProjectName: r.ProjectName,
+		Comment:     r.Comment,
+		Duration:    max(int(at.Sub(r.StartedAt).Seconds()), 0),
+	}
+}
+
+func GetStatePath() string {
+	return filepath.Join(os.Getenv("HOME"), WorklowFolderPath, StateFileName)
+}
+
+// ReadState returns the last written state, or an empty one if there is none.
+func ReadState() (State, error) {
+	var state State
+	content, err := os.ReadFile(GetStatePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal(content, &state)
+	return state, err
+}
+
+// WriteState replaces the state file. It's written to a temporary file first,
+// so a concurrently running prompt never reads half of it.
+func WriteState(state State) error {
+	err := os.MkdirAll(filepath.Join(os.Getenv("HOME"), WorklowFolderPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tempFile := GetStatePath() + ".tmp"
+	err = os.WriteFile(tempFile, content, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempFile, GetStatePath())
+}
+
+// lastTimeEntry returns the entry resume continues: the one stopped most
+// recently if it's among the given ones, otherwise the one listed last.
+func lastTimeEntry(timeEntries []aerion.TimeEntry, lastStoppedId int) (aerion.TimeEntry, bool) {
+	for _, timeEntry := range timeEntries {
+		if lastStoppedId != 0 && timeEntry.Id == lastStoppedId {
+			return timeEntry, true
+		}
+	}
+	if len(timeEntries) == 0 {
+		return aerion.TimeEntry{}, false
+	}
+	return timeEntries[len(timeEntries)-1], true
+}
+
+// NewState creates the state from today's time entries as fetched at the
+// given time, given the ID of the entry stopped most recently.
+func NewState(timeEntries []aerion.TimeEntry, names *ProjectNames, fetchedAt time.Time, lastStoppedId int) (State, error) {
+	state := State{UpdatedAt: fetchedAt, Day: fetchedAt.Format(DayFormat), LastStoppedId: lastStoppedId}
+	for _, timeEntry := range timeEntries {
+		state.Total += timeEntry.Duration
+		if !timeEntry.Running || state.Running != nil {
+			continue
+		}
+
+		name, err := names.Name(timeEntry.ProjectId)
+		if err != nil {
+			return state, err
+		}
+		state.Running = &RunningState{
+			Alias:       names.Alias(timeEntry.ProjectId),
+			ProjectName: name,
+			Comment:     timeEntry.Comment,
+			StartedAt:   fetchedAt.Add(-time.Duration(timeEntry.Duration) * time.Second),
+		}
+	}
+
+	if last, ok := lastTimeEntry(timeEntries, lastStoppedId); ok {
+		name, err := names.Name(last.ProjectId)
+		if err != nil {
+			return state, err
+		}
+		state.Last = &LastState{
+			Alias:       names.Alias(last.ProjectId),
+			ProjectName: name,
+			Comment:     last.Comment,
+			Duration:    last.Duration,
+		}
+	}
+	return state, nil
+}
+
+// saveState writes the state for today's time entries. While offline
+// operations are pending, the API doesn't know about them yet, so the state
+// they left is kept. The state is only a cache for the prompt, so failures are
+// ignored.
+func saveState(timeEntries []aerion.TimeEntry, names *ProjectNames, fetchedAt time.Time) {
+	if journal, err := ReadJournal(); err != nil || len(journal) > 0 {
+		return
+	}
+	previous, _ := ReadState()
+	state, err := NewState(timeEntries, names, fetchedAt, previous.LastStoppedId)
+	if err == nil {
+		WriteState(state)
+	}
+}
+
+// recordStoppedTimeEntry remembers the entry that was just stopped, for resume.
+// Unlike the rest of the state, it's also written while offline operations are
+// synced, as they are replayed in order.
+func recordStoppedTimeEntry(id int) {
+	state, err := ReadState()
+	if err == nil {
+		state.LastStoppedId = id
+		WriteState(state)
+	}
+}
+
+// refreshState fetches today's time entries to write the state after they were
+// changed.
+func refreshState(client *AerionClient) {
+	timeEntries, err := client.GetTodaysTimeEntries()
+	if err == nil {
+		saveState(timeEntries, NewProjectNames(client), time.Now())
+	}
+}
+
+// recordStateOffline updates the state for an operation that was recorded in
+// the journal, so the prompt reflects it before it's synced.
+func recordStateOffline(entry JournalEntry) {
+	state, err := ReadState()
+	if err != nil {
+		return
+	}
+
+	if state.Day != entry.At.Format(DayFormat) {
+		// resuming on a new day continues the last entry in a new one
+		last := state.Last
+		if state.Running != nil {
+			last = state.Running.stopped(entry.At)
+		}
+		if last != nil {
+			last.Duration = 0
+		}
+		state = State{Day: entry.At.Format(DayFormat), Last: last, LastStoppedId: state.LastStoppedId}
+	} else if state.Running != nil {
+		state.Total += int(entry.At.Sub(state.UpdatedAt).Seconds())
+	}
+	state.UpdatedAt = entry.At
+
+	switch entry.Operation {
+	case JournalStop:
+		if state.Running != nil {
+			state.Last = state.Running.stopped(entry.At)
+		}
+		state.Running = nil
+	case JournalStart:
+		if state.Running == nil || state.Running.Alias != entry.Alias {
+			project, _ := findProjectByAlias(entry.Alias)
+			state.Running = &RunningState{
+				Alias:       entry.Alias,
+				ProjectName: project.Name,
+				Comment:     entry.Comment,
+				StartedAt:   entry.At,
+			}
+		}
+	case JournalResume:
+		if state.Running == nil && state.Last != nil {
+			state.Running = &RunningState{
+				Alias:       state.Last.Alias,
+				ProjectName: state.Last.ProjectName,
+				Comment:     state.Last.Comment,
+				StartedAt:   entry.At.Add(-time.Duration(state.Last.Duration) * time.Second),
+			}
+		}
+	}
+
+	WriteState(state)
+}
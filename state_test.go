@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPromptData(t *testing.T) {
+	now := time.Date(2026, 10, 7, 14, 0, 0, 0, time.Local)
+	state := State{
+		UpdatedAt: now.Add(-10 * time.Minute),
+		Day:       "2026-10-07",
+		Total:     4 * 3600,
+		Running: &RunningState{
+			Alias:       "p1",
+			ProjectName: "Project One",
+			StartedAt:   now.Add(-90 * time.Minute),
+		},
+	}
+
+	data := newPromptData(state, now, 5*time.Minute)
+	if !data.Running || data.Alias != "p1" || data.Elapsed != 90*60 || data.Total != 4*3600+10*60 || !data.Stale {
+		t.Fatalf("unexpected prompt data %+v", data)
+	}
+
+	state.Running = nil
+	data = newPromptData(state, now, time.Hour)
+	if data.Running || data.Elapsed != 0 || data.Total != 4*3600 || data.Stale {
+		t.Fatalf("unexpected prompt data %+v", data)
+	}
+
+	// a state of yesterday doesn't count for today
+	data = newPromptData(state, now.AddDate(0, 0, 1), 0)
+	if data.Total != 0 || data.Stale {
+		t.Fatalf("unexpected prompt data %+v", data)
+	}
+
+	if data := newPromptData(State{}, now, 0); !data.Stale || data.Running {
+		t.Fatalf("expected a missing state to be stale, got %+v", data)
+	}
+}
+
+func TestRecordStateOfflineResumesLastEntry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 10, 7, 14, 0, 0, 0, time.Local)
+	err := WriteState(State{
+		UpdatedAt: now.Add(-time.Hour),
+		Day:       "2026-10-07",
+		Total:     2 * 3600,
+		Running:   &RunningState{Alias: "p1", ProjectName: "Project One", StartedAt: now.Add(-90 * time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recordStateOffline(JournalEntry{Operation: JournalStop, At: now.Add(-30 * time.Minute)})
+	recordStateOffline(JournalEntry{Operation: JournalResume, At: now})
+	state, err := ReadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := newPromptData(state, now, time.Hour)
+	if !data.Running || data.Alias != "p1" || data.Elapsed != 60*60 || data.Total != 2*3600+30*60 {
+		t.Fatalf("unexpected prompt data %+v", data)
+	}
+
+	recordStateOffline(JournalEntry{Operation: JournalResume, At: now.AddDate(0, 0, 1)})
+	state, _ = ReadState()
+	data = newPromptData(state, now.AddDate(0, 0, 1), time.Hour)
+	if !data.Running || data.Alias != "p1" || data.Elapsed != 0 {
+		t.Fatalf("expected the entry to be continued on the next day, got %+v", data)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// templateFuncs are the helpers available in --format templates.
+var templateFuncs = template.FuncMap{
+	// hm formats seconds like "01h 30m"
+	"hm": formatHoursMinutes,
+	// short formats seconds like "1h 30m", leaving out zero parts
+	"short": SecondsToHoursMinutes,
+	// clock formats seconds like "1:30"
+	"clock": formatClock,
+	// hours formats seconds as decimal hours like "1.50"
+	"hours": func(seconds int) string {
+		return fmt.Sprintf("%.2f", float64(seconds)/3600)
+	},
+	// date formats a day like "2026-10-01" with a Go time layout
+	"date": func(layout string, day string) string {
+		date, err := time.Parse(DayFormat, day)
+		if err != nil {
+			return day
+		}
+		return date.Format(layout)
+	},
+	// truncate shortens text to at most length characters, ending with "…"
+	"truncate": func(length int, text string) string {
+		if utf8.RuneCountInString(text) <= length {
+			return text
+		}
+		if length < 1 {
+			return ""
+		}
+		return string([]rune(text)[:length-1]) + "…"
+	},
+	// pad fills text up with spaces to the given length
+	"pad": func(length int, text string) string {
+		return fmt.Sprintf("%-*s", length, text)
+	},
+	// oneline joins the lines of a multi-line comment
+	"oneline": func(text string) string {
+		return strings.ReplaceAll(text, "\n", " ")
+	},
+}
+
+func parseFormatTemplate(format string) (*template.Template, error) {
+	return template.New("format").Funcs(templateFuncs).Parse(format)
+}
+
+// writeTemplate executes the template once per item, each followed by a
+// newline.
+func writeTemplate[T any](w io.Writer, tmpl *template.Template, items []T) error {
+	for _, item := range items {
+		err := tmpl.Execute(w, item)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
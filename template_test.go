@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+)
+
+func TestFormatTemplateHelpers(t *testing.T) {
+	view := TimeEntryView{
+		TimeEntry: aerion.TimeEntry{Day: "2026-10-01", Duration: 5430, Comment: "- Fix the login\n- Review"},
+		Alias:     "p1",
+	}
+
+	tests := map[string]string{
+		"{{.Alias}} {{.Duration | hm}} {{.Comment | oneline}}":              "p1 01h 30m - Fix the login - Review",
+		"{{.Duration | short}} {{.Duration | clock}} {{.Duration | hours}}": "1h 30m 1:30 1.51",
+		`{{.Day | date "Mon 02.01."}}`:                                      "Thu 01.10.",
+		"{{.Comment | oneline | truncate 10}}|":                             "- Fix the…|",
+		"{{.Alias | pad 4}}|{{or .ProjectName .Alias}}":                     "p1  |p1",
+	}
+
+	for format, expected := range tests {
+		tmpl, err := parseFormatTemplate(format)
+		if err != nil {
+			t.Errorf("parseFormatTemplate(%q) error: %v", format, err)
+			continue
+		}
+		var out bytes.Buffer
+		if err := writeTemplate(&out, tmpl, []TimeEntryView{view}); err != nil {
+			t.Errorf("executing %q failed: %v", format, err)
+			continue
+		}
+		if out.String() != expected+"\n" {
+			t.Errorf("%q rendered %q, expected %q", format, out.String(), expected)
+		}
+	}
+}
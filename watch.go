@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/ttacon/chalk"
+	"golang.org/x/term"
+)
+
+// ANSI sequences for the full-screen watch mode.
+const (
+	enterAlternateScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAlternateScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen          = "\x1b[H\x1b[2J"
+)
+
+// dashboard is the state of the `today --watch` screen.
+type dashboard struct {
+	client *AerionClient
+	names  *ProjectNames
+	color  bool
+	// projects are the aliased projects, in the order of their number keys
+	projects    []ProjectConfig
+	timeEntries []aerion.TimeEntry
+	fetchedAt   time.Time
+	status      string
+}
+
+func newDashboard(client *AerionClient, color bool) *dashboard {
+	cfg, _ := ReadConfig()
+	var projects []ProjectConfig
+	for _, project := range cfg.Projects {
+		if project.Alias != "" {
+			projects = append(projects, project)
+		}
+	}
+	slices.SortFunc(projects, func(a, b ProjectConfig) int {
+		return strings.Compare(a.Alias, b.Alias)
+	})
+
+	return &dashboard{
+		client:   client,
+		names:    NewProjectNames(client),
+		color:    color,
+		projects: projects[:min(len(projects), 9)],
+	}
+}
+
+// refresh fetches today's time entries. On failure, the last ones are kept
+// and the error is shown in the status line.
+func (d *dashboard) refresh() {
+	timeEntries, err := d.client.GetTodaysTimeEntries()
+	if aerion.IsOffline(err) {
+		d.status = chalk.Yellow.Color("Aerion is unreachable, showing the entries from " + d.fetchedAt.Format("15:04:05"))
+		return
+	}
+	if err != nil {
+		message, _ := describeApiError(err)
+		d.status = chalk.Red.Color(message)
+		return
+	}
+
+	d.timeEntries = timeEntries
+	d.fetchedAt = time.Now()
+	saveState(timeEntries, d.names, d.fetchedAt)
+}
+
+// render draws the screen as of now. Running entries tick on from the
+// duration they had when they were fetched.
+func (d *dashboard) render(now time.Time) (string, error) {
+	var screen bytes.Buffer
+	fmt.Fprintf(&screen, "Today, %s\n\n", now.Format("Monday 2006-01-02 15:04:05"))
+
+	timeEntries := slices.Clone(d.timeEntries)
+	for i := range timeEntries {
+		if timeEntries[i].Running {
+			timeEntries[i].Duration += int(now.Sub(d.fetchedAt).Seconds())
+		}
+	}
+	if len(timeEntries) == 0 {
+		fmt.Fprintln(&screen, "No time entries for today")
+	} else {
+		err := printTimeEntries(&screen, timeEntries, d.names, d.color, false)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Fprintln(&screen)
+	var keys []string
+	for i, project := range d.projects {
+		keys = append(keys, fmt.Sprintf("[%d] %s", i+1, project.Alias))
+	}
+	keys = append(keys, "[s] stop", "[r] resume", "[q] quit")
+	fmt.Fprintln(&screen, strings.Join(keys, "  "))
+	if d.status != "" {
+		fmt.Fprintln(&screen, d.status)
+	}
+
+	// the terminal is in raw mode, which doesn't return the carriage on "\n"
+	return strings.ReplaceAll(screen.String(), "\n", "\r\n"), nil
+}
+
+// handleKey runs the operation bound to a key and reports whether to quit.
+func (d *dashboard) handleKey(key byte) bool {
+	entry := JournalEntry{At: time.Now()}
+	switch {
+	case key == 'q' || key == 3 || key == 4: // Ctrl-C and Ctrl-D
+		return true
+	case key == 's':
+		entry.Operation = JournalStop
+		d.status = "Stopped"
+	case key == 'r':
+		entry.Operation = JournalResume
+		d.status = "Resumed"
+	case key >= '1' && int(key-'1') < len(d.projects):
+		entry.Operation = JournalStart
+		entry.Alias = d.projects[key-'1'].Alias
+		entry.Amend = true
+		d.status = "Switched to " + entry.Alias
+	default:
+		return false
+	}
+
+	// the operations print what they do, which would garble the screen
+	recorded, err := withoutStdout(func() (bool, error) {
+		return tryRunOrRecord(d.client, entry)
+	})
+	if recorded && err == nil {
+		d.status = chalk.Yellow.Color("Aerion is unreachable. Recorded '" + entry.String() + "' to be synced later.")
+	} else if err != nil {
+		message, _ := describeApiError(err)
+		d.status = chalk.Red.Color(message)
+	}
+	d.refresh()
+	return false
+}
+
+// withoutStdout runs fn with stdout discarded.
+func withoutStdout(fn func() (bool, error)) (bool, error) {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return fn()
+	}
+	defer devNull.Close()
+
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = stdout
+	}()
+	return fn()
+}
+
+// watchToday shows today's time entries full-screen until the user quits,
+// with running timers ticking every second and the entries fetched again
+// every pollInterval to pick up changes made elsewhere.
+func watchToday(client *AerionClient, color bool, pollInterval time.Duration) error {
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) {
+		return errors.New("--watch needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(stdin)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(stdin, state)
+
+	fmt.Print(enterAlternateScreen)
+	defer fmt.Print(leaveAlternateScreen)
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			if n == 1 {
+				keys <- buf[0]
+			}
+		}
+	}()
+
+	dashboard := newDashboard(client, color)
+	dashboard.refresh()
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	for {
+		screen, err := dashboard.render(time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Print(clearScreen + screen)
+
+		select {
+		case key, ok := <-keys:
+			if !ok || dashboard.handleKey(key) {
+				return nil
+			}
+		case <-poll.C:
+			dashboard.status = ""
+			dashboard.refresh()
+		case <-tick.C:
+		}
+	}
+}
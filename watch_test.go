@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+)
+
+func TestDashboardTicksRunningEntries(t *testing.T) {
+	env := setupE2E(t)
+	client, _ := newClient(aerion.NetworkConfig{})
+	today := time.Now().Format(DayFormat)
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[0].Id, UserId: 1, Day: today, Duration: 600, Sorting: 1})
+	env.server.AddTimeEntry(aerion.TimeEntry{ProjectId: env.projects[1].Id, UserId: 1, Day: today, Duration: 3540, Sorting: 2, Running: true})
+
+	dashboard := newDashboard(client, false)
+	dashboard.refresh()
+
+	screen, err := dashboard.render(dashboard.fetchedAt.Add(90 * time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"p1         |    00h 10m", "p2         | ⌛ 01h 00m", "total      |    01h 10m", "[1] p1  [2] p2  [s] stop"} {
+		if !strings.Contains(screen, expected) {
+			t.Errorf("expected %q in screen %q", expected, screen)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(screen, "\r\n", ""), "\n") {
+		t.Errorf("expected raw mode line endings in %q", screen)
+	}
+}
+
+func TestDashboardKeysStartAndStopProjects(t *testing.T) {
+	env := setupE2E(t)
+	client, _ := newClient(aerion.NetworkConfig{})
+
+	dashboard := newDashboard(client, false)
+	dashboard.refresh()
+
+	if dashboard.handleKey('1') {
+		t.Fatal("expected the dashboard to keep running")
+	}
+	if len(dashboard.timeEntries) != 1 || !dashboard.timeEntries[0].Running || dashboard.timeEntries[0].ProjectId != env.projects[0].Id {
+		t.Fatalf("expected p1 to run, got %+v", dashboard.timeEntries)
+	}
+
+	dashboard.handleKey('2')
+	dashboard.handleKey('s')
+	timeEntries := env.server.TimeEntries()
+	if len(timeEntries) != 2 || timeEntries[0].Running || timeEntries[1].Running || timeEntries[1].ProjectId != env.projects[1].Id {
+		t.Fatalf("unexpected time entries %+v", timeEntries)
+	}
+
+	if !dashboard.handleKey('q') {
+		t.Fatal("expected q to quit")
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fischeversenker/aerion-cli/aerion"
+	"github.com/jxskiss/mcli"
+	"github.com/ttacon/chalk"
+)
+
+const weekCellWidth = 9
+
+func WeekCommand() {
+	var args struct {
+		Color bool     `cli:"-c, --color, enable colors in the output"`
+		Week  []string `cli:"week, Any day of the week to summarize, e.g. last week or 2026-10-05 (default: this week)"`
+	}
+	_, err := mcli.Parse(&args)
+	if err != nil {
+		panic(err)
+	}
+
+	now := time.Now()
+	day, _, err := ParseDateRange(strings.Join(args.Week, " "), now)
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+	monday := startOfWeek(day)
+	sunday := monday.AddDate(0, 0, 6)
+
+	client := loggedInClient()
+	if client == nil {
+		return
+	}
+
+	timeEntries, err := client.GetTimeEntriesBetween(monday.Format(DayFormat), sunday.Format(DayFormat))
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	cfg, _ := ReadConfig()
+	targets, err := cfg.Schedule.Targets()
+	if err != nil {
+		fmt.Println(chalk.Red.Color(err.Error()))
+		os.Exit(ExitInvalidInput)
+	}
+
+	matrix, err := newWeekMatrix(monday, timeEntries, targets, NewProjectNames(client))
+	if err != nil {
+		exitOnApiError(err)
+	}
+
+	fmt.Printf("Week of %s to %s\n\n", monday.Format(DayFormat), sunday.Format(DayFormat))
+	matrix.print(args.Color)
+
+	missingDays := matrix.missingDays(now)
+	if len(missingDays) > 0 {
+		fmt.Println()
+		for _, day := range missingDays {
+			message := "No time entries on " + day.Format("Monday, 2006-01-02")
+			if args.Color {
+				message = chalk.Yellow.Color(message)
+			}
+			fmt.Println(message)
+		}
+	}
+}
+
+// weekMatrix holds the booked seconds per project and weekday, Monday first.
+type weekMatrix struct {
+	monday   time.Time
+	projects []string
+	seconds  map[string]*[7]int
+	// targets are the seconds to work per weekday, indexed by time.Weekday
+	targets [7]int
+}
+
+func newWeekMatrix(monday time.Time, timeEntries []aerion.TimeEntry, targets [7]int, names *ProjectNames) (*weekMatrix, error) {
+	matrix := &weekMatrix{monday: monday, seconds: map[string]*[7]int{}, targets: targets}
+	for _, timeEntry := range timeEntries {
+		day, err := time.ParseInLocation(DayFormat, timeEntry.Day, monday.Location())
+		if err != nil {
+			continue
+		}
+		weekday := int(day.Sub(monday).Hours()+12) / 24
+		if weekday < 0 || weekday > 6 {
+			continue
+		}
+
+		project, err := names.Display(timeEntry.ProjectId)
+		if err != nil {
+			return nil, err
+		}
+		if matrix.seconds[project] == nil {
+			matrix.seconds[project] = &[7]int{}
+			matrix.projects = append(matrix.projects, project)
+		}
+		matrix.seconds[project][weekday] += timeEntry.Duration
+	}
+	slices.Sort(matrix.projects)
+	return matrix, nil
+}
+
+func (m *weekMatrix) dayTotal(weekday int) int {
+	var total int
+	for _, seconds := range m.seconds {
+		total += seconds[weekday]
+	}
+	return total
+}
+
+func (m *weekMatrix) isWorkingDay(weekday int) bool {
+	return m.targets[m.monday.AddDate(0, 0, weekday).Weekday()] > 0
+}
+
+// weekdays returns the columns to show: the working days of the schedule,
+// plus the other days that have time entries.
+func (m *weekMatrix) weekdays() []int {
+	var weekdays []int
+	for weekday := 0; weekday < 7; weekday++ {
+		if m.isWorkingDay(weekday) || m.dayTotal(weekday) > 0 {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	return weekdays
+}
+
+// missingDays returns the working days up to now that have no time entries.
+func (m *weekMatrix) missingDays(now time.Time) []time.Time {
+	var missing []time.Time
+	for weekday := 0; weekday < 7; weekday++ {
+		day := m.monday.AddDate(0, 0, weekday)
+		if day.After(now) {
+			break
+		}
+		if m.isWorkingDay(weekday) && m.dayTotal(weekday) == 0 {
+			missing = append(missing, day)
+		}
+	}
+	return missing
+}
+
+func (m *weekMatrix) print(color bool) {
+	labelWidth := 10
+	for _, project := range m.projects {
+		labelWidth = max(labelWidth, len(project))
+	}
+	weekdays := m.weekdays()
+
+	dim := func(text string) string {
+		if color {
+			return chalk.Dim.TextStyle(text)
+		}
+		return text
+	}
+	cell := func(seconds int) string {
+		if seconds == 0 {
+			return fmt.Sprintf("%*s", weekCellWidth, "-")
+		}
+		return fmt.Sprintf("%*s", weekCellWidth, formatHoursMinutes(seconds))
+	}
+
+	header := fmt.Sprintf("%-*s |", labelWidth, "")
+	for _, weekday := range weekdays {
+		day := m.monday.AddDate(0, 0, weekday)
+		label := day.Format("Mon 02")
+		if m.dayTotal(weekday) == 0 && m.isWorkingDay(weekday) {
+			label = "!" + label
+		}
+		header += fmt.Sprintf("%*s", weekCellWidth, label)
+	}
+	header += fmt.Sprintf(" |%*s", weekCellWidth, "total")
+	fmt.Println(dim(header))
+
+	var overallTime int
+	for _, project := range m.projects {
+		var projectTime int
+		row := fmt.Sprintf("%-*s |", labelWidth, project)
+		for _, weekday := range weekdays {
+			row += cell(m.seconds[project][weekday])
+			projectTime += m.seconds[project][weekday]
+		}
+		overallTime += projectTime
+		fmt.Printf("%s |%s\n", row, cell(projectTime))
+	}
+
+	footer := fmt.Sprintf("%-*s |", labelWidth, "total")
+	for _, weekday := range weekdays {
+		footer += cell(m.dayTotal(weekday))
+	}
+	fmt.Println(dim(fmt.Sprintf("%s |%s", footer, cell(overallTime))))
+}